@@ -0,0 +1,155 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"stravid.com/besserliste/types"
+)
+
+// Undo and redo show and revert at most this many actions at once.
+const maxStackedActions = 20
+
+// itemAction groups the item changes of one request, undoing the action reverts all of them together.
+// Kind is "do" for changes made by hand, "undo" and "redo" for reverts and "system" for changes which are not on any undo stack.
+// The action is stored together with its first change, requests which end up not changing anything leave no empty actions behind.
+type itemAction struct {
+	Id               int64
+	UserId           int
+	Kind             string
+	RevertedActionId int64
+}
+
+func newItemAction(userId int, kind string) *itemAction {
+	return &itemAction{UserId: userId, Kind: kind}
+}
+
+// recordItemChange stores the change of an item as part of the action.
+func (env *Environment) recordItemChange(tx *sql.Tx, action *itemAction, itemId int64, dimensionId int, quantity int64, note string, state string) error {
+	if action.Id == 0 {
+		result, err := env.queries.InsertItemAction(tx, action.UserId, action.Kind, action.RevertedActionId)
+		if err != nil {
+			return err
+		}
+
+		action.Id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+	}
+
+	return env.queries.InsertItemChange(tx, itemId, action.UserId, action.Id, dimensionId, quantity, note, state)
+}
+
+// revertActions undoes or redoes the newest actions on the stack of the user, one after the other, until it reverted the given action.
+// Items someone else changed in the meantime are conflicts, they are reported through formErrors and the transaction must not be committed.
+// The returned events must be published once the transaction is committed.
+func (env *Environment) revertActions(tx *sql.Tx, householdId int, userId int, kind string, untilActionId int, formErrors map[string]string) ([]itemEvent, error) {
+	entries, err := env.queries.GetActionStack(tx, userId, kind, maxStackedActions)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := types.GroupItemActions(entries)
+	count := 0
+	for index, action := range actions {
+		if action.Id == untilActionId {
+			count = index + 1
+		}
+	}
+
+	// The page showing the stack is outdated, for example because the user reverted the action on another device.
+	if count == 0 {
+		formErrors["actions"] = "Die gewählte Änderung ist nicht mehr verfügbar, bitte erneut wählen."
+		return nil, nil
+	}
+
+	events := []itemEvent{}
+	for _, action := range actions[:count] {
+		actionEvents, err := env.revertAction(tx, householdId, userId, kind, &action, formErrors)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(formErrors) != 0 {
+			return nil, nil
+		}
+
+		events = append(events, actionEvents...)
+	}
+
+	return events, nil
+}
+
+// revertAction puts every item the action changed back into the state it had before the action.
+// Redoing reverts the undo, which brings back the state the original action left behind.
+func (env *Environment) revertAction(tx *sql.Tx, householdId int, userId int, kind string, action *types.ItemAction, formErrors map[string]string) ([]itemEvent, error) {
+	revertedItems, err := env.queries.GetActionItems(tx, int64(action.Id), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	verb := "rückgängig gemacht"
+	if kind == "redo" {
+		verb = "wiederhergestellt"
+	}
+
+	reverting := &itemAction{UserId: userId, Kind: kind, RevertedActionId: int64(action.Id)}
+	events := []itemEvent{}
+	for _, revertedItem := range revertedItems {
+		item, err := env.queries.GetItem(tx, householdId, revertedItem.ItemId)
+		if err != nil {
+			return nil, err
+		}
+
+		if revertedItem.ConflictingUserName != "" {
+			formErrors["actions"] = fmt.Sprintf("%s wurde inzwischen von %s geändert, deshalb kann die Änderung vom %s nicht %s werden.", item.NamePlural, revertedItem.ConflictingUserName, action.FormattedRecordedAt(), verb)
+			return nil, nil
+		}
+
+		if revertedItem.State == "added" {
+			addedItem, err := env.queries.GetAddedItemByProductDimension(tx, item.ListId, item.ProductId, revertedItem.DimensionId)
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					return nil, err
+				}
+			} else if addedItem.Id != item.Id {
+				formErrors["actions"] = fmt.Sprintf("%s steht inzwischen wieder auf der Liste, deshalb kann die Änderung vom %s nicht %s werden.", item.NamePlural, action.FormattedRecordedAt(), verb)
+				return nil, nil
+			}
+		}
+
+		restoredItem := *item
+		restoredItem.State = revertedItem.State
+		restoredItem.Quantity = revertedItem.Quantity
+		restoredItem.Note = revertedItem.Note
+		restoredItem.Dimension = types.Dimension{Id: revertedItem.DimensionId}
+
+		if item.State == "gathered" && restoredItem.State != "gathered" {
+			err = env.stockItem(tx, item, "gathered", "added")
+			if err != nil {
+				return nil, err
+			}
+		} else if item.State != "gathered" && restoredItem.State == "gathered" {
+			err = env.stockItem(tx, &restoredItem, "added", "gathered")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = env.queries.RestoreItem(tx, item.Id, restoredItem.State, revertedItem.DimensionId, revertedItem.Quantity, revertedItem.Note)
+		if err != nil {
+			return nil, err
+		}
+
+		err = env.recordItemChange(tx, reverting, int64(item.Id), revertedItem.DimensionId, int64(revertedItem.Quantity), revertedItem.Note, revertedItem.State)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, itemEvent{ListId: item.ListId, ItemId: item.Id, State: restoredItem.State, UserId: userId})
+	}
+
+	return events, nil
+}
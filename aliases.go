@@ -0,0 +1,97 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"stravid.com/besserliste/types"
+)
+
+// addProductAlias lets the product be found under another name as well.
+// Names of products and other aliases cannot be used, otherwise a name would stand for two products.
+func (env *Environment) addProductAlias(tx *sql.Tx, householdId int, product *types.ProductDetails, name string, formErrors map[string]string) error {
+	if name == "" {
+		formErrors["name"] = "Namen angeben"
+	} else if utf8.RuneCountInString(name) > 40 {
+		formErrors["name"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
+	}
+
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	existingProduct, err := env.queries.GetProductByName(tx, householdId, name)
+	if err == nil {
+		formErrors["name"] = fmt.Sprintf("Anderen Namen angeben (steht bereits für %s)", existingProduct.NamePlural)
+		return nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	err = env.queries.InsertProductAlias(tx, householdId, product.Id, name)
+	if err != nil {
+		if err.Error() == "UNIQUE constraint failed: index 'idx_product_aliases_name'" {
+			formErrors["name"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	return env.indexProduct(tx, int64(product.Id), product.NameSingular, product.NamePlural)
+}
+
+// removeProductAlias ignores aliases which do not exist (anymore).
+func (env *Environment) removeProductAlias(tx *sql.Tx, householdId int, aliasId int) error {
+	alias, err := env.queries.GetProductAlias(tx, householdId, aliasId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	err = env.queries.RemoveProductAlias(tx, householdId, alias.Id)
+	if err != nil {
+		return err
+	}
+
+	product, err := env.queries.GetProductDetails(tx, householdId, alias.ProductId)
+	if err != nil {
+		return err
+	}
+
+	return env.indexProduct(tx, int64(product.Id), product.NameSingular, product.NamePlural)
+}
+
+// keepNamesAsAliases adds the names of a merged product as aliases of the surviving one, so searching for the old names still finds it.
+func (env *Environment) keepNamesAsAliases(tx *sql.Tx, householdId int, mergedProduct *types.ProductDetails, survivingProduct *types.ProductDetails) error {
+	names := []string{mergedProduct.NameSingular}
+	if !strings.EqualFold(mergedProduct.NamePlural, mergedProduct.NameSingular) {
+		names = append(names, mergedProduct.NamePlural)
+	}
+
+	for _, name := range names {
+		if strings.EqualFold(name, survivingProduct.NameSingular) || strings.EqualFold(name, survivingProduct.NamePlural) {
+			continue
+		}
+
+		_, err := env.queries.GetProductAliasByName(tx, householdId, name)
+		if err == nil {
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		err = env.queries.InsertProductAlias(tx, householdId, survivingProduct.Id, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return env.indexProduct(tx, int64(survivingProduct.Id), survivingProduct.NameSingular, survivingProduct.NamePlural)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"stravid.com/besserliste/types"
+)
+
+var errMissingIdempotencyKey = errors.New("Header `Idempotency-Key` mit 32 Zeichen angeben.")
+
+func respondWithJson(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func respondWithJsonError(w http.ResponseWriter, statusCode int, err error) {
+	if statusCode >= http.StatusInternalServerError {
+		log.Println(fmt.Sprintf("%s\n%s", err.Error(), debug.Stack()))
+	}
+
+	respondWithJson(w, statusCode, struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   http.StatusText(statusCode),
+		Message: err.Error(),
+	})
+}
+
+func respondWithJsonFormErrors(w http.ResponseWriter, formErrors map[string]string) {
+	respondWithJson(w, http.StatusUnprocessableEntity, struct {
+		Error   string            `json:"error"`
+		Message string            `json:"message"`
+		Errors  map[string]string `json:"errors"`
+	}{
+		Error:   http.StatusText(http.StatusUnprocessableEntity),
+		Message: "Es gibt ein Problem",
+		Errors:  formErrors,
+	})
+}
+
+// respondWithJsonReplay answers a request whose `Idempotency-Key` was processed before. Like the HTML forms we treat it as success without changing anything.
+func respondWithJsonReplay(w http.ResponseWriter) {
+	respondWithJson(w, http.StatusOK, struct {
+		Replayed bool `json:"replayed"`
+	}{
+		Replayed: true,
+	})
+}
+
+func respondWithJsonMethodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	respondWithJsonError(w, http.StatusMethodNotAllowed, fmt.Errorf("Methode muss `%s` sein.", allowed))
+}
+
+func decodeJsonBody(r *http.Request, data interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(data)
+}
+
+// apiIdempotencyKey reads the `Idempotency-Key` header which carries the same value the HTML forms send as `_idempotency_key`.
+func apiIdempotencyKey(r *http.Request) (string, error) {
+	key := r.Header.Get("Idempotency-Key")
+	if len(key) != 32 {
+		return "", errMissingIdempotencyKey
+	}
+
+	return key, nil
+}
+
+func (env *Environment) requireApiAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !env.isAuthenticated(r) {
+			respondWithJsonError(w, http.StatusUnauthorized, errors.New("Anmeldung erforderlich."))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiTransitionItem backs the JSON counterparts of CheckItemRoute, RemoveItemRoute and UndoRoute.
+func (env *Environment) apiTransitionItem(w http.ResponseWriter, r *http.Request, itemId int, oldState string, newState string) {
+	idempotencyKey, err := apiIdempotencyKey(r)
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	item, err := env.transitionItem(tx, user.HouseholdId, user.Id, itemId, oldState, newState)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithJsonError(w, http.StatusNotFound, errors.New("Eintrag existiert nicht."))
+		} else if errors.Is(err, errWrongItemState) {
+			respondWithJsonError(w, http.StatusConflict, err)
+		} else {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+	if err != nil {
+		if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+			respondWithJsonReplay(w)
+			return
+		} else {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	env.events.publish(itemEvent{ListId: item.ListId, ItemId: item.Id, State: item.State, UserId: user.Id})
+
+	respondWithJson(w, http.StatusOK, item)
+}
+
+// apiList picks the list with the given id or, when no id is given, the current list of the session.
+func apiList(r *http.Request, listId int) (types.List, error) {
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	if listId == 0 {
+		return list, nil
+	}
+
+	for _, l := range lists {
+		if l.Id == listId {
+			return l, nil
+		}
+	}
+
+	return types.List{}, errors.New("Liste existiert nicht.")
+}
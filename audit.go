@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+
+	"stravid.com/besserliste/types"
+)
+
+// maxAuditEntries limits the screen, the CSV export contains every matching change.
+const maxAuditEntries = 500
+
+var auditStateOptions = []FormOption{
+	{Id: "", Name: "Alle Änderungen"},
+	{Id: "added", Name: "Aufgeschrieben"},
+	{Id: "gathered", Name: "Abgehakt"},
+	{Id: "removed", Name: "Entfernt"},
+	{Id: "product", Name: "Produktänderungen"},
+}
+
+// writeAuditCsv uses semicolons and decimal commas, that is what spreadsheets with German settings expect.
+func writeAuditCsv(w io.Writer, entries []types.AuditEntry) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	err := writer.Write([]string{"Zeitpunkt", "Person", "Produkt", "Liste", "Änderung", "Zustand", "Menge", "Notiz", "Preis"})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = writer.Write([]string{
+			entry.RecordedAt.Local().Format("2006-01-02 15:04:05"),
+			entry.UserName,
+			entry.FormattedName(),
+			entry.ListName,
+			entry.Description(),
+			entry.State,
+			entry.FormattedQuantity(),
+			entry.Note,
+			entry.FormattedPrice(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
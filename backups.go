@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"stravid.com/besserliste/migrations"
+)
+
+const backupFilePrefix = "besserliste-"
+const backupFileSuffix = ".db"
+const backupTimestampLayout = "20060102-150405"
+
+// backupDatabase writes a consistent copy of the database to path through the SQLite online backup API, which is safe while the web server writes.
+// The copy is written next to path first, so a crash never leaves a half written backup behind.
+func backupDatabase(db *sql.DB, path string) error {
+	_, err := os.Stat(path)
+	if err == nil {
+		return fmt.Errorf("Backup `%s` already exists", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	temporaryPath := path + ".tmp"
+	os.Remove(temporaryPath)
+
+	destination, err := sql.Open("sqlite3", temporaryPath)
+	if err != nil {
+		return err
+	}
+
+	err = copyDatabase(db, destination)
+	closeErr := destination.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temporaryPath)
+		return err
+	}
+
+	return os.Rename(temporaryPath, path)
+}
+
+// restoreDatabase replaces the whole database with the backup and migrates it if the backup is older than this version of Besserliste.
+// Backups from a newer version are refused, their schema would not match the queries.
+func (env *Environment) restoreDatabase(path string) error {
+	_, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	source, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	var integrity string
+	err = source.QueryRow("PRAGMA integrity_check;").Scan(&integrity)
+	if err != nil {
+		return fmt.Errorf("Backup `%s` cannot be read: %w", path, err)
+	}
+
+	if integrity != "ok" {
+		return fmt.Errorf("Backup `%s` is damaged: %s", path, integrity)
+	}
+
+	var backupVersion int
+	err = source.QueryRow("PRAGMA user_version;").Scan(&backupVersion)
+	if err != nil {
+		return err
+	}
+
+	var currentVersion int
+	err = env.db.QueryRow("PRAGMA user_version;").Scan(&currentVersion)
+	if err != nil {
+		return err
+	}
+
+	if backupVersion == 0 {
+		return fmt.Errorf("Backup `%s` is not a Besserliste database", path)
+	}
+
+	if backupVersion > currentVersion {
+		return fmt.Errorf("Backup `%s` has schema version %d, this version of Besserliste only knows %d", path, backupVersion, currentVersion)
+	}
+
+	err = copyDatabase(source, env.db)
+	if err != nil {
+		return err
+	}
+
+	// The backup itself is the state from before migrating, there is no need for another one.
+	return migrations.Run(env.db, nil)
+}
+
+// preMigrationBackup backs up the database into directory before migrations change it.
+// The backups are not named like scheduled ones, so removeOldBackups leaves them alone.
+func preMigrationBackup(db *sql.DB, directory string) func(version int) (string, error) {
+	return func(version int) (string, error) {
+		path := filepath.Join(directory, fmt.Sprintf("pre-migration-%d-%s.db", version, time.Now().UTC().Format(backupTimestampLayout)))
+		return path, backupDatabase(db, path)
+	}
+}
+
+// copyDatabase copies all pages in one step, so the source is read in a single consistent snapshot.
+func copyDatabase(source *sql.DB, destination *sql.DB) error {
+	ctx := context.Background()
+
+	sourceConnection, err := source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sourceConnection.Close()
+
+	destinationConnection, err := destination.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destinationConnection.Close()
+
+	return destinationConnection.Raw(func(destinationDriverConnection interface{}) error {
+		return sourceConnection.Raw(func(sourceDriverConnection interface{}) error {
+			backup, err := destinationDriverConnection.(*sqlite3.SQLiteConn).Backup("main", sourceDriverConnection.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+
+			_, err = backup.Step(-1)
+			if err != nil {
+				backup.Finish()
+				return err
+			}
+
+			return backup.Finish()
+		})
+	})
+}
+
+// backupScheduler writes a snapshot into directory every interval and keeps the newest retention snapshots.
+// The first snapshot is due one interval after the newest one in directory, so restarting more often than the interval does not skip backups.
+// Failed backups are logged instead of stopping the web application, the next interval tries again.
+func (env *Environment) backupScheduler(ctx context.Context, directory string, interval time.Duration, retention int) {
+	delay, err := firstBackupDelay(directory, interval, time.Now())
+	if err != nil {
+		log.Println("Error reading backups: ", err.Error())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = interval
+
+		path := filepath.Join(directory, backupFilePrefix+time.Now().UTC().Format(backupTimestampLayout)+backupFileSuffix)
+		err := backupDatabase(env.db, path)
+		if err != nil {
+			log.Println("Error backing up database: ", err.Error())
+			continue
+		}
+
+		err = removeOldBackups(directory, retention)
+		if err != nil {
+			log.Println("Error removing old backups: ", err.Error())
+		}
+	}
+}
+
+// firstBackupDelay returns how long the newest snapshot in directory has left until it is interval old.
+// Without a readable snapshot the backup is due right away.
+func firstBackupDelay(directory string, interval time.Duration, now time.Time) (time.Duration, error) {
+	backups, err := listBackups(directory)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(backups) == 0 {
+		return 0, nil
+	}
+
+	takenAt, err := time.Parse(backupTimestampLayout, strings.TrimSuffix(strings.TrimPrefix(backups[0], backupFilePrefix), backupFileSuffix))
+	if err != nil {
+		return 0, nil
+	}
+
+	delay := takenAt.Add(interval).Sub(now)
+	if delay < 0 {
+		return 0, nil
+	}
+
+	return delay, nil
+}
+
+// removeOldBackups relies on the timestamp in the file names sorting like the time they were taken.
+// Files in the directory which were not written by backupScheduler are left alone.
+func removeOldBackups(directory string, retention int) error {
+	backups, err := listBackups(directory)
+	if err != nil {
+		return err
+	}
+
+	for index, name := range backups {
+		if index < retention {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(directory, name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listBackups returns the names of the snapshots written by backupScheduler, newest first.
+func listBackups(directory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupFilePrefix) && strings.HasSuffix(name, backupFileSuffix) {
+			backups = append(backups, name)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
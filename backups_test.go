@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func writeTestFiles(t *testing.T, directory string, names []string) {
+	for _, name := range names {
+		err := os.WriteFile(filepath.Join(directory, name), []byte{}, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRemoveOldBackups(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFiles(t, directory, []string{
+		"besserliste-20260101-000000.db",
+		"besserliste-20260103-000000.db",
+		"besserliste-20260102-000000.db",
+		"besserliste-20260104-000000.db",
+		"pre-migration-5-20260101-000000.db",
+		"besserliste.db",
+		"notes.txt",
+	})
+
+	err := removeOldBackups(directory, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	expected := []string{
+		"besserliste-20260103-000000.db",
+		"besserliste-20260104-000000.db",
+		"besserliste.db",
+		"notes.txt",
+		"pre-migration-5-20260101-000000.db",
+	}
+
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("%v instead of %v", names, expected)
+	}
+}
+
+func TestFirstBackupDelay(t *testing.T) {
+	now := time.Date(2026, 1, 4, 12, 0, 0, 0, time.UTC)
+	interval := 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		files []string
+		delay time.Duration
+	}{
+		{name: "no backups", files: []string{"besserliste.db"}, delay: 0},
+		{name: "recent backup", files: []string{"besserliste-20260103-000000.db", "besserliste-20260104-060000.db"}, delay: 18 * time.Hour},
+		{name: "outdated backup", files: []string{"besserliste-20260102-000000.db"}, delay: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			writeTestFiles(t, directory, test.files)
+
+			delay, err := firstBackupDelay(directory, interval, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if delay != test.delay {
+				t.Fatalf("%s instead of %s", delay, test.delay)
+			}
+		})
+	}
+}
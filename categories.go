@@ -0,0 +1,173 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+
+	"stravid.com/besserliste/types"
+)
+
+var errLastCategory = errors.New("Die letzte Kategorie kann nicht gelöscht werden.")
+var errCategoryInUse = errors.New("Kategorie ist die einzige Kategorie mancher Produkte, diese zuerst einer anderen Kategorie zuordnen.")
+var errCategoriesChanged = errors.New("Die Kategorien wurden inzwischen geändert, Seite neu laden und erneut sortieren.")
+
+// addCategory appends a category to the end of the walking order.
+// Invalid input is reported through formErrors and leaves the categories untouched.
+func (env *Environment) addCategory(tx *sql.Tx, householdId int, name string, formErrors map[string]string) error {
+	validateCategoryName(name, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	_, err := env.queries.InsertCategory(tx, householdId, name)
+	if err != nil && isDuplicateCategoryName(err, formErrors) {
+		return nil
+	}
+
+	return err
+}
+
+func (env *Environment) renameCategory(tx *sql.Tx, householdId int, categoryId int, name string, formErrors map[string]string) error {
+	validateCategoryName(name, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	err := env.queries.UpdateCategory(tx, householdId, categoryId, name)
+	if err != nil && isDuplicateCategoryName(err, formErrors) {
+		return nil
+	}
+
+	return err
+}
+
+// removeCategory deletes a category unless a product would be left without any category.
+// Categories of other households are sql.ErrNoRows, the queries removing its links are not scoped by household.
+func (env *Environment) removeCategory(tx *sql.Tx, householdId int, categoryId int) error {
+	_, err := env.queries.GetCategory(tx, householdId, categoryId)
+	if err != nil {
+		return err
+	}
+
+	categories, err := env.queries.GetCategories(tx, householdId)
+	if err != nil {
+		return err
+	}
+
+	if len(categories) <= 1 {
+		return errLastCategory
+	}
+
+	exclusiveProducts, err := env.queries.CountExclusiveCategoryProducts(tx, categoryId)
+	if err != nil {
+		return err
+	}
+
+	if exclusiveProducts != 0 {
+		return errCategoryInUse
+	}
+
+	err = env.queries.RemoveCategoryProducts(tx, categoryId)
+	if err != nil {
+		return err
+	}
+
+	err = env.queries.RemoveCategoryStores(tx, categoryId)
+	if err != nil {
+		return err
+	}
+
+	return env.queries.RemoveCategory(tx, householdId, categoryId)
+}
+
+// reorderCategories stores the given order of all categories of the household, new stores start out with it.
+// The ids have to be exactly the current categories, otherwise the order was built from an outdated page.
+func (env *Environment) reorderCategories(tx *sql.Tx, householdId int, categoryIds []string) error {
+	categories, err := env.queries.GetCategories(tx, householdId)
+	if err != nil {
+		return err
+	}
+
+	orderedIds, err := parseCategoryOrder(categories, categoryIds)
+	if err != nil {
+		return err
+	}
+
+	// Orderings are unique per household, so they are moved out of the way before being reassigned.
+	err = env.queries.ReleaseCategoryOrderings(tx, householdId)
+	if err != nil {
+		return err
+	}
+
+	for index, categoryId := range orderedIds {
+		err = env.queries.SetCategoryOrdering(tx, householdId, categoryId, index+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseCategoryOrder checks that the ids are exactly the given categories and returns them in the submitted order.
+func parseCategoryOrder(categories []types.Category, categoryIds []string) ([]int, error) {
+	if len(categoryIds) != len(categories) {
+		return nil, errCategoriesChanged
+	}
+
+	categorySet := map[string]bool{}
+	for _, category := range categories {
+		categorySet[strconv.Itoa(category.Id)] = true
+	}
+
+	orderedIds := []int{}
+	for _, id := range categoryIds {
+		if !categorySet[id] {
+			return nil, errCategoriesChanged
+		}
+		delete(categorySet, id)
+
+		categoryId, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, err
+		}
+		orderedIds = append(orderedIds, categoryId)
+	}
+
+	return orderedIds, nil
+}
+
+// moveCategory swaps the category with its neighbour in the given order, used by the buttons when dragging is not available.
+func moveCategory(categoryIds []string, id string, offset int) []string {
+	moved := append([]string{}, categoryIds...)
+	for index := range moved {
+		if moved[index] == id {
+			target := index + offset
+			if target >= 0 && target < len(moved) {
+				moved[index], moved[target] = moved[target], moved[index]
+			}
+			break
+		}
+	}
+
+	return moved
+}
+
+func validateCategoryName(name string, formErrors map[string]string) {
+	if name == "" {
+		formErrors["name"] = "Namen angeben"
+	} else if utf8.RuneCountInString(name) > 20 {
+		formErrors["name"] = "Kürzeren Namen angeben (maximal 20 Zeichen)"
+	}
+}
+
+func isDuplicateCategoryName(err error, formErrors map[string]string) bool {
+	if err.Error() == "UNIQUE constraint failed: index 'idx_categories_name'" {
+		formErrors["name"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const defaultConfigurationPath = "config.json"
+
+// Configuration is read from config.json and the environment, see docs/configuration.txt for every field and its default.
+// TLS is only used when TlsCertificate and TlsKey point to PEM files.
+type Configuration struct {
+	Database            string
+	Secret              string
+	Listen              string
+	TlsCertificate      string
+	TlsKey              string
+	BackupDirectory     string
+	BackupIntervalHours int
+	BackupRetention     int
+
+	// Password is the shared first-login password of earlier versions, it is ignored so their config.json keeps loading.
+	Password string
+}
+
+// configurationVariables maps every field to the environment variable overriding it.
+// Fields are either *string or *int.
+var configurationVariables = []struct {
+	name  string
+	field func(*Configuration) interface{}
+}{
+	{"BESSERLISTE_DATABASE", func(c *Configuration) interface{} { return &c.Database }},
+	{"BESSERLISTE_SECRET", func(c *Configuration) interface{} { return &c.Secret }},
+	{"BESSERLISTE_LISTEN", func(c *Configuration) interface{} { return &c.Listen }},
+	{"BESSERLISTE_TLS_CERTIFICATE", func(c *Configuration) interface{} { return &c.TlsCertificate }},
+	{"BESSERLISTE_TLS_KEY", func(c *Configuration) interface{} { return &c.TlsKey }},
+	{"BESSERLISTE_BACKUP_DIRECTORY", func(c *Configuration) interface{} { return &c.BackupDirectory }},
+	{"BESSERLISTE_BACKUP_INTERVAL_HOURS", func(c *Configuration) interface{} { return &c.BackupIntervalHours }},
+	{"BESSERLISTE_BACKUP_RETENTION", func(c *Configuration) interface{} { return &c.BackupRetention }},
+}
+
+// loadConfiguration starts from the defaults, applies the file and then the environment variables.
+// A missing file is only fine when it is the default one, containers can then be configured through the environment alone.
+func loadConfiguration(path string, explicitPath bool) (Configuration, error) {
+	configuration := Configuration{
+		Database:            "besserliste.db",
+		Listen:              ":5000",
+		BackupIntervalHours: 24,
+		BackupRetention:     7,
+	}
+
+	configurationFile, err := os.Open(path)
+	if err != nil {
+		if explicitPath || !errors.Is(err, os.ErrNotExist) {
+			return configuration, fmt.Errorf("Error opening %s: %w", path, err)
+		}
+	} else {
+		defer configurationFile.Close()
+
+		// Unknown fields are most likely typos, silently ignoring them would leave the default in place.
+		decoder := json.NewDecoder(configurationFile)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&configuration)
+		if err != nil {
+			return configuration, fmt.Errorf("Error reading %s: %w", path, err)
+		}
+	}
+
+	if configuration.Password != "" {
+		log.Println(fmt.Sprintf("Ignoring Password in %s, users log in with their own password, set it with `besserliste set-password <email>`", path))
+		configuration.Password = ""
+	}
+
+	for _, variable := range configurationVariables {
+		value, ok := os.LookupEnv(variable.name)
+		if !ok {
+			continue
+		}
+
+		switch field := variable.field(&configuration).(type) {
+		case *string:
+			*field = value
+		case *int:
+			*field, err = strconv.Atoi(value)
+			if err != nil {
+				return configuration, fmt.Errorf("Error reading %s: `%s` is not a number", variable.name, value)
+			}
+		}
+	}
+
+	err = validateConfiguration(configuration)
+	if err != nil {
+		return configuration, fmt.Errorf("Invalid configuration: %w", err)
+	}
+
+	return configuration, nil
+}
+
+func validateConfiguration(configuration Configuration) error {
+	// The session cookie is signed and encrypted with the secret, which only works with these key sizes.
+	secretLength := len(configuration.Secret)
+	if secretLength != 32 && secretLength != 64 {
+		return fmt.Errorf("Secret has to be 32 or 64 bytes long, it has %d", secretLength)
+	}
+
+	_, port, err := net.SplitHostPort(configuration.Listen)
+	if err != nil {
+		return fmt.Errorf("Listen has to be an address like `:5000` or `127.0.0.1:5000`: %w", err)
+	}
+
+	parsedPort, err := strconv.Atoi(port)
+	if err != nil || parsedPort < 0 || parsedPort > 65535 {
+		return fmt.Errorf("Listen has to contain a port between 0 and 65535, not `%s`", port)
+	}
+
+	if configuration.Database == "" {
+		return errors.New("Database has to be the path of the SQLite file")
+	}
+
+	// SQLite creates a missing database file, but not the directory it lives in.
+	directory, err := os.Stat(filepath.Dir(configuration.Database))
+	if err != nil || !directory.IsDir() {
+		return fmt.Errorf("Database has to be in an existing directory, `%s` is not", filepath.Dir(configuration.Database))
+	}
+
+	if (configuration.TlsCertificate == "") != (configuration.TlsKey == "") {
+		return errors.New("TlsCertificate and TlsKey have to be set together")
+	}
+
+	for _, path := range []string{configuration.TlsCertificate, configuration.TlsKey} {
+		if path == "" {
+			continue
+		}
+
+		_, err = os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("TlsCertificate and TlsKey have to be readable files: %w", err)
+		}
+	}
+
+	if configuration.BackupDirectory != "" {
+		directory, err := os.Stat(configuration.BackupDirectory)
+		if err != nil || !directory.IsDir() {
+			return fmt.Errorf("BackupDirectory has to be an existing directory, `%s` is not", configuration.BackupDirectory)
+		}
+	}
+
+	if configuration.BackupIntervalHours < 1 {
+		return errors.New("BackupIntervalHours has to be at least 1")
+	}
+
+	if configuration.BackupRetention < 1 {
+		return errors.New("BackupRetention has to be at least 1")
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfiguration(t *testing.T) {
+	directory := t.TempDir()
+	database := filepath.Join(directory, "besserliste.db")
+	secret := strings.Repeat("a", 64)
+
+	tests := []struct {
+		name          string
+		file          string
+		environment   map[string]string
+		configuration Configuration
+		err           string
+	}{
+		{
+			name:          "file only",
+			file:          `{"Database": "` + database + `", "Secret": "` + secret + `", "Listen": "127.0.0.1:5055"}`,
+			configuration: Configuration{Database: database, Secret: secret, Listen: "127.0.0.1:5055", BackupIntervalHours: 24, BackupRetention: 7},
+		},
+		{
+			name:          "environment overrides file",
+			file:          `{"Database": "` + database + `", "Secret": "` + secret + `", "Listen": "127.0.0.1:5055", "BackupRetention": 3}`,
+			environment:   map[string]string{"BESSERLISTE_LISTEN": ":8080", "BESSERLISTE_BACKUP_RETENTION": "14"},
+			configuration: Configuration{Database: database, Secret: secret, Listen: ":8080", BackupIntervalHours: 24, BackupRetention: 14},
+		},
+		{
+			name:          "legacy shared password",
+			file:          `{"Database": "` + database + `", "Secret": "` + secret + `", "Password": "test"}`,
+			configuration: Configuration{Database: database, Secret: secret, Listen: ":5000", BackupIntervalHours: 24, BackupRetention: 7},
+		},
+		{
+			name: "bad port",
+			file: `{"Database": "` + database + `", "Secret": "` + secret + `", "Listen": ":70000"}`,
+			err:  "Invalid configuration: Listen has to contain a port between 0 and 65535, not `70000`",
+		},
+		{
+			name: "bad secret length",
+			file: `{"Database": "` + database + `", "Secret": "64 character hex string"}`,
+			err:  "Invalid configuration: Secret has to be 32 or 64 bytes long, it has 23",
+		},
+		{
+			name:        "bad number in environment",
+			file:        `{"Database": "` + database + `", "Secret": "` + secret + `"}`,
+			environment: map[string]string{"BESSERLISTE_BACKUP_RETENTION": "seven"},
+			err:         "Error reading BESSERLISTE_BACKUP_RETENTION: `seven` is not a number",
+		},
+		{
+			name: "unknown field",
+			file: `{"Database": "` + database + `", "Secret": "` + secret + `", "Databse": "typo.db"}`,
+			err:  "Error reading",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setenv restores the variables of the surrounding environment after the test.
+			for _, variable := range configurationVariables {
+				t.Setenv(variable.name, "")
+				os.Unsetenv(variable.name)
+			}
+
+			for name, value := range test.environment {
+				t.Setenv(name, value)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.json")
+			err := os.WriteFile(path, []byte(test.file), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			configuration, err := loadConfiguration(path, true)
+			if test.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+					t.Fatalf("%v instead of %s", err, test.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if configuration != test.configuration {
+				t.Fatalf("%+v instead of %+v", configuration, test.configuration)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	_, err := loadConfiguration(path, true)
+	if err == nil || !strings.HasPrefix(err.Error(), "Error opening") {
+		t.Fatalf("%v instead of an error opening the file", err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sync"
+)
+
+// itemEvent tells clients showing a list that one of its items changed.
+type itemEvent struct {
+	ListId int    `json:"list_id"`
+	ItemId int    `json:"item_id"`
+	State  string `json:"state"`
+	UserId int    `json:"user_id"`
+}
+
+// eventBroker fans item events out to the open `/events` streams of this process.
+type eventBroker struct {
+	mutex       sync.Mutex
+	subscribers map[chan itemEvent]int
+	closed      bool
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		subscribers: make(map[chan itemEvent]int),
+	}
+}
+
+func (broker *eventBroker) subscribe(listId int) chan itemEvent {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	events := make(chan itemEvent, 16)
+	if broker.closed {
+		close(events)
+	} else {
+		broker.subscribers[events] = listId
+	}
+	return events
+}
+
+func (broker *eventBroker) unsubscribe(events chan itemEvent) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	delete(broker.subscribers, events)
+}
+
+// publish must only be called after the transaction containing the change is committed.
+// Slow subscribers miss events instead of blocking the request, they refetch the whole list on the next one anyway.
+func (broker *eventBroker) publish(event itemEvent) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	for events, listId := range broker.subscribers {
+		if listId != event.ListId {
+			continue
+		}
+
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// close ends all open streams, otherwise they would keep a graceful shutdown waiting until it times out.
+func (broker *eventBroker) close() {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.closed = true
+	for events := range broker.subscribers {
+		close(events)
+		delete(broker.subscribers, events)
+	}
+}
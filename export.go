@@ -0,0 +1,363 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bump exportVersion whenever a table or column is added to exportTables, import refuses documents of other versions.
+const exportVersion = 1
+
+// exportTable describes how one table is written to and read from an export.
+// The queries are built from these descriptions instead of living in queries/, there would be two for every table otherwise.
+type exportTable struct {
+	name  string
+	hasId bool
+	// columns lists every column except id.
+	columns []string
+	// references maps columns to the table whose ids they contain, imports replace them with the new ids.
+	references map[string]string
+	// Rows with the same values in matchBy reuse the existing row instead of being inserted, migrations already create dimensions and units.
+	matchBy []string
+}
+
+// exportTables is in dependency order, every table only references tables before it or itself.
+// Search trigrams are left out because they are rebuilt from the product names, failed logins and idempotency keys because they expire anyway.
+var exportTables = []exportTable{
+	{name: "dimensions", hasId: true, columns: []string{"name", "ordering"}, matchBy: []string{"name"}},
+	{name: "units", hasId: true, columns: []string{"dimension_id", "name_singular", "name_plural", "conversion_to_base", "conversion_from_base", "ordering"}, references: map[string]string{"dimension_id": "dimensions"}, matchBy: []string{"dimension_id", "name_singular"}},
+	{name: "households", hasId: true, columns: []string{"name"}},
+	{name: "users", hasId: true, columns: []string{"household_id", "name", "email", "password_hash"}, references: map[string]string{"household_id": "households"}},
+	{name: "categories", hasId: true, columns: []string{"household_id", "name", "ordering"}, references: map[string]string{"household_id": "households"}},
+	{name: "lists", hasId: true, columns: []string{"household_id", "name", "ordering"}, references: map[string]string{"household_id": "households"}},
+	{name: "stores", hasId: true, columns: []string{"household_id", "name"}, references: map[string]string{"household_id": "households"}},
+	{name: "stores_categories", columns: []string{"store_id", "category_id", "ordering"}, references: map[string]string{"store_id": "stores", "category_id": "categories"}},
+	{name: "products", hasId: true, columns: []string{"household_id", "name_singular", "name_plural"}, references: map[string]string{"household_id": "households"}},
+	{name: "categories_products", columns: []string{"category_id", "product_id"}, references: map[string]string{"category_id": "categories", "product_id": "products"}},
+	{name: "dimensions_products", columns: []string{"dimension_id", "product_id"}, references: map[string]string{"dimension_id": "dimensions", "product_id": "products"}},
+	{name: "product_aliases", hasId: true, columns: []string{"household_id", "product_id", "name"}, references: map[string]string{"household_id": "households", "product_id": "products"}},
+	{name: "recipes", hasId: true, columns: []string{"household_id", "name", "servings"}, references: map[string]string{"household_id": "households"}},
+	{name: "recipe_ingredients", hasId: true, columns: []string{"recipe_id", "product_id", "dimension_id", "quantity"}, references: map[string]string{"recipe_id": "recipes", "product_id": "products", "dimension_id": "dimensions"}},
+	{name: "stock_levels", hasId: true, columns: []string{"product_id", "dimension_id", "quantity", "minimum_quantity", "list_id"}, references: map[string]string{"product_id": "products", "dimension_id": "dimensions", "list_id": "lists"}},
+	{name: "recurring_items", hasId: true, columns: []string{"list_id", "product_id", "dimension_id", "user_id", "quantity", "interval_days", "next_due_at"}, references: map[string]string{"list_id": "lists", "product_id": "products", "dimension_id": "dimensions", "user_id": "users"}},
+	{name: "items", hasId: true, columns: []string{"list_id", "product_id", "dimension_id", "quantity", "note", "state", "changed_at"}, references: map[string]string{"list_id": "lists", "product_id": "products", "dimension_id": "dimensions"}},
+	{name: "item_actions", hasId: true, columns: []string{"user_id", "kind", "reverted_action_id", "recorded_at"}, references: map[string]string{"user_id": "users", "reverted_action_id": "item_actions"}},
+	{name: "item_changes", hasId: true, columns: []string{"item_id", "user_id", "action_id", "dimension_id", "quantity", "note", "price", "state", "recorded_at"}, references: map[string]string{"item_id": "items", "user_id": "users", "action_id": "item_actions", "dimension_id": "dimensions"}},
+	{name: "product_changes", hasId: true, columns: []string{"product_id", "user_id", "name_singular", "name_plural", "recorded_at"}, references: map[string]string{"product_id": "products", "user_id": "users"}},
+}
+
+type exportDocument struct {
+	Version    int                                 `json:"version"`
+	ExportedAt time.Time                           `json:"exported_at"`
+	Tables     map[string][]map[string]interface{} `json:"tables"`
+}
+
+func (table exportTable) allColumns() []string {
+	if table.hasId {
+		return append([]string{"id"}, table.columns...)
+	}
+
+	return table.columns
+}
+
+// exportData writes every household with its complete history to path.
+func (env *Environment) exportData(path string) error {
+	tx, err := env.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	document := exportDocument{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Tables:     make(map[string][]map[string]interface{}),
+	}
+
+	for _, table := range exportTables {
+		columns := table.allColumns()
+
+		// The unary plus drops the declared column type, otherwise the driver would turn DATETIME columns into time.Time and they would not round-trip.
+		expressions := []string{}
+		for _, column := range columns {
+			expressions = append(expressions, fmt.Sprintf("+%s AS %s", column, column))
+		}
+
+		rows, err := tx.Query(fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", strings.Join(expressions, ", "), table.name, strings.Join(columns, ", ")))
+		if err != nil {
+			return err
+		}
+
+		tableRows := []map[string]interface{}{}
+		for rows.Next() {
+			values := make([]interface{}, len(columns))
+			pointers := make([]interface{}, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+
+			err = rows.Scan(pointers...)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			row := make(map[string]interface{})
+			for i, column := range columns {
+				if bytes, ok := values[i].([]byte); ok {
+					row[column] = string(bytes)
+				} else {
+					row[column] = values[i]
+				}
+			}
+
+			tableRows = append(tableRows, row)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		document.Tables[table.name] = tableRows
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(document)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// importData replaces all households with the ones from the export at path, dimensions and units are matched by name.
+// The whole document is validated before the first write, the import then runs in a single transaction.
+func (env *Environment) importData(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	document := exportDocument{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&document)
+	if err != nil {
+		return fmt.Errorf("Export `%s` cannot be read: %w", path, err)
+	}
+
+	err = validateExportDocument(&document)
+	if err != nil {
+		return fmt.Errorf("Export `%s` is invalid: %w", path, err)
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM product_trigrams")
+	if err != nil {
+		return err
+	}
+
+	for i := len(exportTables) - 1; i >= 0; i-- {
+		if exportTables[i].matchBy != nil {
+			continue
+		}
+
+		_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s", exportTables[i].name))
+		if err != nil {
+			return err
+		}
+	}
+
+	newIds := make(map[string]map[int64]int64)
+	for _, table := range exportTables {
+		newIds[table.name] = make(map[int64]int64)
+
+		for index, row := range document.Tables[table.name] {
+			values := make(map[string]interface{})
+			for _, column := range table.columns {
+				value := importValue(row[column])
+				if referencedTable, ok := table.references[column]; ok && value != nil {
+					value = newIds[referencedTable][value.(int64)]
+				}
+				values[column] = value
+			}
+
+			existingId := int64(0)
+			if table.matchBy != nil {
+				conditions := []string{}
+				arguments := []interface{}{}
+				for _, column := range table.matchBy {
+					conditions = append(conditions, fmt.Sprintf("%s = ?", column))
+					arguments = append(arguments, values[column])
+				}
+
+				err = tx.QueryRow(fmt.Sprintf("SELECT id FROM %s WHERE %s", table.name, strings.Join(conditions, " AND ")), arguments...).Scan(&existingId)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+			}
+
+			if existingId == 0 {
+				placeholders := []string{}
+				arguments := []interface{}{}
+				for _, column := range table.columns {
+					placeholders = append(placeholders, "?")
+					arguments = append(arguments, values[column])
+				}
+
+				result, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.name, strings.Join(table.columns, ", "), strings.Join(placeholders, ", ")), arguments...)
+				if err != nil {
+					return fmt.Errorf("Row %d of `%s` cannot be imported: %w", index+1, table.name, err)
+				}
+
+				existingId, err = result.LastInsertId()
+				if err != nil {
+					return err
+				}
+			}
+
+			if table.hasId {
+				newIds[table.name][importValue(row["id"]).(int64)] = existingId
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return env.indexProducts()
+}
+
+// validateExportDocument sorts the rows by id and checks that every reference points to a row of the document.
+// Sorting lets item actions reference the actions they revert, those always have a lower id.
+func validateExportDocument(document *exportDocument) error {
+	if document.Version != exportVersion {
+		return fmt.Errorf("version %d is not supported, only version %d is", document.Version, exportVersion)
+	}
+
+	known := make(map[string]bool)
+	for _, table := range exportTables {
+		known[table.name] = true
+	}
+
+	for name := range document.Tables {
+		if !known[name] {
+			return fmt.Errorf("unknown table `%s`", name)
+		}
+	}
+
+	ids := make(map[string]map[int64]bool)
+	for _, table := range exportTables {
+		ids[table.name] = make(map[int64]bool)
+		rows := document.Tables[table.name]
+
+		if table.hasId {
+			for index, row := range rows {
+				if _, ok := importValue(row["id"]).(int64); !ok {
+					return fmt.Errorf("row %d of `%s` has no integer id", index+1, table.name)
+				}
+			}
+
+			sort.SliceStable(rows, func(i, j int) bool {
+				return importValue(rows[i]["id"]).(int64) < importValue(rows[j]["id"]).(int64)
+			})
+		}
+
+		columns := make(map[string]bool)
+		for _, column := range table.allColumns() {
+			columns[column] = true
+		}
+
+		for index, row := range rows {
+			for column := range row {
+				if !columns[column] {
+					return fmt.Errorf("row %d of `%s` has unknown column `%s`", index+1, table.name, column)
+				}
+			}
+
+			for column := range columns {
+				value, ok := row[column]
+				if !ok {
+					return fmt.Errorf("row %d of `%s` has no column `%s`", index+1, table.name, column)
+				}
+
+				switch value.(type) {
+				case nil, string, json.Number:
+				default:
+					return fmt.Errorf("row %d of `%s` has an invalid value in `%s`", index+1, table.name, column)
+				}
+			}
+
+			for column, referencedTable := range table.references {
+				value := importValue(row[column])
+				if value == nil {
+					continue
+				}
+
+				id, ok := value.(int64)
+				if !ok || !ids[referencedTable][id] {
+					return fmt.Errorf("row %d of `%s` references %v in `%s`, which is not in `%s`", index+1, table.name, row[column], column, referencedTable)
+				}
+			}
+
+			if table.hasId {
+				id := importValue(row["id"]).(int64)
+				if ids[table.name][id] {
+					return fmt.Errorf("row %d of `%s` repeats id %d", index+1, table.name, id)
+				}
+				ids[table.name][id] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// importValue turns JSON numbers back into the integers and reals SQLite stored.
+func importValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	integer, err := number.Int64()
+	if err == nil {
+		return integer
+	}
+
+	real, err := number.Float64()
+	if err == nil {
+		return real
+	}
+
+	return value
+}
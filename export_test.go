@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// seedTestHousehold creates a household with products, an alias, a store, a recipe, stock and items with their history.
+func seedTestHousehold(t *testing.T, env *Environment) {
+	householdId, userId, err := env.addHousehold("Test", "Test", "test@example.com", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	lists, err := env.queries.GetLists(tx, int(householdId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formErrors := make(map[string]string)
+	productIds := []int{
+		addTestProduct(t, env, tx, int(householdId), int(userId), "Tomate", "Tomaten"),
+		addTestProduct(t, env, tx, int(householdId), int(userId), "Gurke", "Gurken"),
+	}
+
+	for _, productId := range productIds {
+		product, err := env.queries.GetProduct(tx, int(householdId), productId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		itemId, err := env.addItem(tx, int(userId), lists[0].Id, product, strconv.Itoa(product.Dimensions[0].Units[0].Id), "2", "", formErrors)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = env.transitionItem(tx, int(householdId), int(userId), int(itemId), "added", "gathered")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	product, err := env.queries.GetProduct(tx, int(householdId), productIds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.addItem(tx, int(userId), lists[0].Id, product, strconv.Itoa(product.Dimensions[0].Units[0].Id), "1", "reif", formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	productDetails, err := env.queries.GetProductDetails(tx, int(householdId), productIds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.addProductAlias(tx, int(householdId), productDetails, "Paradeiser", formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.addStore(tx, int(householdId), "Markt", formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipeId, err := env.addRecipe(tx, int(householdId), "Salat", "2", formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipe, err := env.queries.GetRecipe(tx, int(householdId), int(recipeId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.addRecipeIngredient(tx, recipe, product, strconv.Itoa(product.Dimensions[0].Units[0].Id), "3", formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(formErrors) != 0 {
+		t.Fatalf("%v instead of no form errors", formErrors)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exportTestDocument(t *testing.T, env *Environment) exportDocument {
+	path := filepath.Join(t.TempDir(), "export.json")
+	err := env.exportData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	document := exportDocument{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	err = decoder.Decode(&document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func importTestDocument(t *testing.T, env *Environment, document exportDocument) error {
+	path := filepath.Join(t.TempDir(), "import.json")
+	content, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path, content, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return env.importData(path)
+}
+
+// shiftTestIds moves every id of the document by offset, so the import has to remap all of them.
+func shiftTestIds(document exportDocument, offset int64) {
+	for _, table := range exportTables {
+		for _, row := range document.Tables[table.name] {
+			columns := []string{}
+			for column := range table.references {
+				columns = append(columns, column)
+			}
+			if table.hasId {
+				columns = append(columns, "id")
+			}
+
+			for _, column := range columns {
+				if id, ok := importValue(row[column]).(int64); ok {
+					row[column] = json.Number(strconv.FormatInt(id+offset, 10))
+				}
+			}
+		}
+	}
+}
+
+func countTestRows(t *testing.T, env *Environment) map[string]int {
+	counts := make(map[string]int)
+	for _, table := range exportTables {
+		var count int
+		err := env.db.QueryRow("SELECT count(*) FROM " + table.name).Scan(&count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[table.name] = count
+	}
+
+	return counts
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := newTestEnvironment(t)
+	seedTestHousehold(t, source)
+	exported := exportTestDocument(t, source)
+
+	for _, name := range []string{"items", "item_changes", "item_actions", "stock_levels", "product_aliases", "recipe_ingredients", "stores"} {
+		if len(exported.Tables[name]) == 0 {
+			t.Fatalf("no rows in `%s` to remap", name)
+		}
+	}
+
+	shifted := exportTestDocument(t, source)
+	shiftTestIds(shifted, 1000)
+
+	destination := newTestEnvironment(t)
+	err := importTestDocument(t, destination, shifted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourceCounts, destinationCounts := countTestRows(t, source), countTestRows(t, destination)
+	if !reflect.DeepEqual(sourceCounts, destinationCounts) {
+		t.Fatalf("%v instead of %v rows", destinationCounts, sourceCounts)
+	}
+
+	rows, err := destination.db.Query("PRAGMA foreign_key_check;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := rows.Next()
+	rows.Close()
+	if violations {
+		t.Fatal("imported rows violate foreign keys")
+	}
+
+	// Remapped ids end up where the original ones were, so every relation survived if both exports are the same.
+	reexported := exportTestDocument(t, destination)
+	if !reflect.DeepEqual(reexported.Tables, exported.Tables) {
+		t.Fatal("export of the imported data differs from the original export")
+	}
+}
+
+func TestImportRejectsUnsupportedVersion(t *testing.T) {
+	env := newTestEnvironment(t)
+	seedTestHousehold(t, env)
+	document := exportTestDocument(t, env)
+	before := countTestRows(t, env)
+
+	document.Version = exportVersion + 1
+	err := importTestDocument(t, env, document)
+	if err == nil || !strings.Contains(err.Error(), "is not supported") {
+		t.Fatalf("%v instead of an unsupported version", err)
+	}
+
+	if after := countTestRows(t, env); !reflect.DeepEqual(after, before) {
+		t.Fatalf("%v instead of %v rows", after, before)
+	}
+}
+
+func TestImportRejectsDanglingReference(t *testing.T) {
+	env := newTestEnvironment(t)
+	seedTestHousehold(t, env)
+	document := exportTestDocument(t, env)
+	before := countTestRows(t, env)
+
+	document.Tables["items"][0]["product_id"] = json.Number("9999")
+	err := importTestDocument(t, env, document)
+	if err == nil || !strings.Contains(err.Error(), "which is not in `products`") {
+		t.Fatalf("%v instead of a dangling reference", err)
+	}
+
+	if after := countTestRows(t, env); !reflect.DeepEqual(after, before) {
+		t.Fatalf("%v instead of %v rows", after, before)
+	}
+}
@@ -5,9 +5,7 @@ go 1.17
 require (
 	github.com/golangcollege/sessions v1.2.0
 	github.com/mattn/go-sqlite3 v1.14.12
+	golang.org/x/crypto v0.0.0-20220427172511-eb4f295cb31f
 )
 
-require (
-	golang.org/x/crypto v0.0.0-20220427172511-eb4f295cb31f // indirect
-	golang.org/x/sys v0.0.0-20220503163025-988cb79eb6c6 // indirect
-)
+require golang.org/x/sys v0.0.0-20220503163025-988cb79eb6c6 // indirect
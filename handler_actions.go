@@ -0,0 +1,137 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+)
+
+func (env *Environment) ActionsRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	undoEntries, err := env.queries.GetActionStack(tx, user.Id, "undo", maxStackedActions)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	redoEntries, err := env.queries.GetActionStack(tx, user.Id, "redo", maxStackedActions)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	renderForm := func(idempotencyKey string, formErrors map[string]string) {
+		files := []string{
+			"screens/actions.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			UndoActions    []types.ItemAction
+			RedoActions    []types.ItemAction
+			IdempotencyKey string
+			FormErrors     map[string]string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			UndoActions:    types.GroupItemActions(undoEntries),
+			RedoActions:    types.GroupItemActions(redoEntries),
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+
+		kind := "undo"
+		untilActionId := r.PostForm.Get("undo_until")
+		if r.PostForm.Has("redo_until") {
+			kind = "redo"
+			untilActionId = r.PostForm.Get("redo_until")
+		}
+
+		parsedUntilActionId, err := strconv.Atoi(untilActionId)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		events, err := env.revertActions(tx, user.HouseholdId, user.Id, kind, parsedUntilActionId, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/actions", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			for _, event := range events {
+				env.events.publish(event)
+			}
+
+			http.Redirect(w, r, "/actions", http.StatusSeeOther)
+		} else {
+			renderForm(IdempotencyKey(), formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm(IdempotencyKey(), make(map[string]string))
+	}
+}
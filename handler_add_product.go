@@ -10,7 +10,6 @@ import (
 	"stravid.com/besserliste/web"
 	"strconv"
 	"strings"
-	"unicode/utf8"
 )
 
 func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request) {
@@ -21,13 +20,15 @@ func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request)
 	}
 	defer tx.Rollback()
 
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
 	err = r.ParseForm()
 	if err != nil {
 		respondWithErrorPage(w, http.StatusBadRequest, err)
 		return
 	}
 
-	categories, err := env.queries.GetCategories(tx)
+	categories, err := env.queries.GetCategories(tx, user.HouseholdId)
 	if err != nil {
 		respondWithErrorPage(w, http.StatusInternalServerError, err)
 		return
@@ -45,9 +46,7 @@ func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request)
 	}
 
 	categoryOptions := []CategoryOption{}
-	categorySet := map[string]bool{}
 	for _, category := range categories {
-		categorySet[strconv.Itoa(category.Id)] = true
 		categoryOptions = append(categoryOptions, CategoryOption{
 			Id:   strconv.Itoa(category.Id),
 			Name: category.Name,
@@ -55,20 +54,21 @@ func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request)
 	}
 
 	dimensionOptions := []FormOption{}
-	dimensionSet := map[string]bool{}
 	for _, dimension := range dimensions {
-		dimensionSet[strconv.Itoa(dimension.Id)] = true
 		dimensionOptions = append(dimensionOptions, FormOption{
 			Id:   strconv.Itoa(dimension.Id),
 			Name: dimension.Name,
 		})
 	}
 
-	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
 
 	renderForm := func(nameSingular string, namePlural string, categoryIds map[string]bool, dimensionIds map[string]bool, idempotencyKey string, formErrors map[string]string) {
 		data := struct {
 			CurrentUser      types.User
+			CurrentList      types.List
+			Lists            []types.List
 			Categories       []CategoryOption
 			DimensionOptions []FormOption
 			NameSingular     string
@@ -79,6 +79,8 @@ func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request)
 			FormErrors       map[string]string
 		}{
 			CurrentUser:      user,
+			CurrentList:      list,
+			Lists:            lists,
 			Categories:       categoryOptions,
 			NameSingular:     nameSingular,
 			NamePlural:       namePlural,
@@ -125,82 +127,13 @@ func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request)
 			selectedCategories[id] = true
 		}
 
-		if nameSingular == "" {
-			formErrors["name_singular"] = "Namen angeben"
-		} else if utf8.RuneCountInString(nameSingular) > 40 {
-			formErrors["name_singular"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
-		}
-
-		if namePlural == "" {
-			formErrors["name_plural"] = "Namen angeben"
-		} else if utf8.RuneCountInString(namePlural) > 40 {
-			formErrors["name_plural"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
-		}
-
-		if len(categoryIds) == 0 {
-			formErrors["category_ids"] = "Kategorie wählen"
-		}
-
-		for _, categoryId := range categoryIds {
-			if !categorySet[categoryId] {
-				formErrors["category_ids"] = "Kategorie wählen"
-			}
-		}
-
-		if len(dimensionIds) == 0 {
-			formErrors["dimension_ids"] = "Größenordnung wählen"
-		}
-
-		for _, dimensionId := range dimensionIds {
-			if !dimensionSet[dimensionId] {
-				formErrors["dimension_ids"] = "Größenordnung wählen"
-			}
+		productId, err := env.addProduct(tx, user.HouseholdId, user.Id, nameSingular, namePlural, categoryIds, dimensionIds, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
 		}
 
 		if len(formErrors) == 0 {
-			result, err := env.queries.InsertProduct(tx, nameSingular, namePlural)
-			if err != nil {
-				if err.Error() == "UNIQUE constraint failed: index 'idx_products_name_singular'" {
-					formErrors["name_singular"] = "Anderen Namen angeben (ist bereits in Verwendung)"
-					renderForm(nameSingular, namePlural, selectedCategories, selectedDimensions, idempotencyKey, formErrors)
-					return
-				} else if err.Error() == "UNIQUE constraint failed: index 'idx_products_name_plural'" {
-					formErrors["name_plural"] = "Anderen Namen angeben (ist bereits in Verwendung)"
-					renderForm(nameSingular, namePlural, selectedCategories, selectedDimensions, idempotencyKey, formErrors)
-					return
-				} else {
-					respondWithErrorPage(w, http.StatusInternalServerError, err)
-					return
-				}
-			}
-			productId, err := result.LastInsertId()
-			if err != nil {
-				respondWithErrorPage(w, http.StatusInternalServerError, err)
-				return
-			}
-
-			for _, id := range dimensionIds {
-				result, err = env.queries.InsertProductDimension(tx, productId, id)
-				if err != nil {
-					respondWithErrorPage(w, http.StatusInternalServerError, err)
-					return
-				}
-			}
-
-			for _, id := range categoryIds {
-				result, err = env.queries.InsertProductCategory(tx, productId, id)
-				if err != nil {
-					respondWithErrorPage(w, http.StatusInternalServerError, err)
-					return
-				}
-			}
-
-			_, err = env.queries.InsertProductChange(tx, productId, user.Id, nameSingular, namePlural)
-			if err != nil {
-				respondWithErrorPage(w, http.StatusInternalServerError, err)
-				return
-			}
-
 			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
 			if err != nil {
 				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
@@ -230,7 +163,7 @@ func (env *Environment) AddProductRoute(w http.ResponseWriter, r *http.Request)
 		}
 	} else {
 		name := r.Form.Get("name")
-		product, err := env.queries.GetProductByName(tx, name)
+		product, err := env.queries.GetProductByName(tx, user.HouseholdId, name)
 
 		err2 := tx.Commit()
 		if err2 != nil {
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+func (env *Environment) AddProductAliasRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	productId, err := strconv.Atoi(r.Form.Get("product-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := env.queries.GetProductDetails(tx, user.HouseholdId, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	successPath := fmt.Sprintf("/edit-product?product-id=%d", product.Id)
+
+	renderForm := func(name string, idempotencyKey string, formErrors map[string]string) {
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			Product        *types.ProductDetails
+			Name           string
+			IdempotencyKey string
+			FormErrors     map[string]string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			Product:        product,
+			Name:           name,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		files := []string{
+			"screens/add_product_alias.html",
+			"layouts/internal.html",
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		name := strings.TrimSpace(r.PostForm.Get("name"))
+
+		err = env.addProductAlias(tx, user.HouseholdId, product, name, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, successPath, http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, successPath, http.StatusSeeOther)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			renderForm(name, idempotencyKey, formErrors)
+		}
+	} else {
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm("", IdempotencyKey(), make(map[string]string))
+	}
+}
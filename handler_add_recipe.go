@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strings"
+)
+
+func (env *Environment) AddRecipeRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	renderForm := func(name string, servings string, idempotencyKey string, formErrors map[string]string) {
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			Name           string
+			Servings       string
+			IdempotencyKey string
+			FormErrors     map[string]string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			Name:           name,
+			Servings:       servings,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		files := []string{
+			"screens/add_recipe.html",
+			"layouts/internal.html",
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		name := strings.TrimSpace(r.PostForm.Get("name"))
+		servings := strings.TrimSpace(r.PostForm.Get("servings"))
+
+		recipeId, err := env.addRecipe(tx, user.HouseholdId, name, servings, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/recipes", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, fmt.Sprintf("/edit-recipe?recipe-id=%d", recipeId), http.StatusSeeOther)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			renderForm(name, servings, idempotencyKey, formErrors)
+		}
+	} else {
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm("", "4", IdempotencyKey(), make(map[string]string))
+	}
+}
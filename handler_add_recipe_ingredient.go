@@ -0,0 +1,184 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+)
+
+// AddRecipeIngredientRoute asks for the product first, the unit options depend on it.
+func (env *Environment) AddRecipeIngredientRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	recipeId, err := strconv.Atoi(r.Form.Get("recipe-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	recipe, err := env.queries.GetRecipe(tx, user.HouseholdId, recipeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	successPath := fmt.Sprintf("/edit-recipe?recipe-id=%d", recipe.Id)
+
+	productOptions := []FormOption{}
+	unitOptions := []FormOption{}
+	var product *types.SelectedProduct
+
+	if r.Form.Get("product-id") == "" {
+		products, err := env.queries.GetProducts(tx, user.HouseholdId)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, option := range products {
+			productOptions = append(productOptions, FormOption{
+				Id:   strconv.Itoa(option.Id),
+				Name: option.NamePlural,
+			})
+		}
+	} else {
+		productId, err := strconv.Atoi(r.Form.Get("product-id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		product, err = env.queries.GetProduct(tx, user.HouseholdId, productId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithErrorPage(w, http.StatusNotFound, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		for _, dimension := range product.Dimensions {
+			for _, unit := range dimension.Units {
+				unitOptions = append(unitOptions, FormOption{
+					Id:   strconv.Itoa(unit.Id),
+					Name: unit.NamePlural,
+				})
+			}
+		}
+	}
+
+	renderForm := func(quantity string, unitId string, idempotencyKey string, formErrors map[string]string) {
+		files := []string{
+			"screens/add_recipe_ingredient.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			Recipe         *types.Recipe
+			Product        *types.SelectedProduct
+			ProductOptions []FormOption
+			UnitOptions    []FormOption
+			IdempotencyKey string
+			FormErrors     map[string]string
+			Quantity       string
+			UnitId         string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			Recipe:         recipe,
+			Product:        product,
+			ProductOptions: productOptions,
+			UnitOptions:    unitOptions,
+			Quantity:       quantity,
+			UnitId:         unitId,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost && product != nil {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		unitId := r.PostForm.Get("unit_id")
+		amount := r.PostForm.Get("quantity")
+
+		err := env.addRecipeIngredient(tx, recipe, product, unitId, amount, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, successPath, http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, successPath, http.StatusSeeOther)
+		} else {
+			renderForm(amount, unitId, idempotencyKey, formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm("", "", IdempotencyKey(), make(map[string]string))
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+	"strings"
+)
+
+func (env *Environment) AddRecipeToListRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	recipeId, err := strconv.Atoi(r.Form.Get("recipe-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	recipe, err := env.queries.GetRecipe(tx, user.HouseholdId, recipeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ingredients, err := env.queries.GetRecipeIngredients(tx, recipe.Id)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	renderForm := func(servings string, idempotencyKey string, formErrors map[string]string) {
+		files := []string{
+			"screens/add_recipe_to_list.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			Recipe         *types.Recipe
+			Ingredients    []types.RecipeIngredient
+			Servings       string
+			IdempotencyKey string
+			FormErrors     map[string]string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			Recipe:         recipe,
+			Ingredients:    ingredients,
+			Servings:       servings,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		servings := strings.TrimSpace(r.PostForm.Get("servings"))
+
+		events, err := env.addRecipeToList(tx, user.Id, list.Id, recipe, servings, formErrors)
+		if err != nil {
+			if errors.Is(err, errRecipeWithoutIngredients) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/plan", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			for _, event := range events {
+				env.events.publish(event)
+			}
+
+			http.Redirect(w, r, "/plan", http.StatusSeeOther)
+		} else {
+			renderForm(servings, idempotencyKey, formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm(strconv.Itoa(recipe.Servings), IdempotencyKey(), make(map[string]string))
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+	"time"
+)
+
+func (env *Environment) AddRecurringItemRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	productId, err := strconv.Atoi(r.Form.Get("product-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := env.queries.GetProduct(tx, user.HouseholdId, productId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	unitOptions := []FormOption{}
+	for _, dimension := range product.Dimensions {
+		for _, unit := range dimension.Units {
+			unitOptions = append(unitOptions, FormOption{
+				Id:   strconv.Itoa(unit.Id),
+				Name: unit.NamePlural,
+			})
+		}
+	}
+
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	renderForm := func(quantity string, unitId string, intervalDays string, startDate string, idempotencyKey string, formErrors map[string]string) {
+		files := []string{
+			"screens/add_recurring_item.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			Product        types.SelectedProduct
+			UnitOptions    []FormOption
+			IdempotencyKey string
+			FormErrors     map[string]string
+			Quantity       string
+			UnitId         string
+			IntervalDays   string
+			StartDate      string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			Product:        *product,
+			UnitOptions:    unitOptions,
+			Quantity:       quantity,
+			UnitId:         unitId,
+			IntervalDays:   intervalDays,
+			StartDate:      startDate,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		unitId := r.PostForm.Get("unit_id")
+		amount := r.PostForm.Get("quantity")
+		intervalDays := r.PostForm.Get("interval_days")
+		startDate := r.PostForm.Get("start_date")
+
+		err := env.addRecurringItem(tx, user.Id, list.Id, product, unitId, amount, intervalDays, startDate, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/recurring-items", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, "/recurring-items", http.StatusSeeOther)
+		} else {
+			renderForm(amount, unitId, intervalDays, startDate, idempotencyKey, formErrors)
+		}
+	} else {
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm("", "", "7", time.Now().Format("2006-01-02"), IdempotencyKey(), make(map[string]string))
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ApiCategoriesRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithJsonMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	categories, err := env.queries.GetCategories(tx, user.HouseholdId)
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, categories)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+)
+
+func (env *Environment) ApiDimensionsRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithJsonMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	dimensions, err := env.queries.GetDimensions(tx)
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, dimensions)
+}
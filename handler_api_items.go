@@ -0,0 +1,148 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ApiItemsRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	switch r.Method {
+	case http.MethodGet:
+		var items []types.AddedItem
+
+		listId := 0
+		if r.URL.Query().Get("list_id") != "" {
+			listId, err = strconv.Atoi(r.URL.Query().Get("list_id"))
+			if err != nil {
+				respondWithJsonError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		list, err := apiList(r, listId)
+		if err != nil {
+			respondWithJsonError(w, http.StatusNotFound, err)
+			return
+		}
+
+		state := r.URL.Query().Get("state")
+		switch state {
+		case "", "added":
+			items, err = env.queries.GetAddedItems(tx, list.Id)
+		case "gathered":
+			items, err = env.queries.GetGatheredItems(tx, list.Id)
+		case "removed":
+			items, err = env.queries.GetRemovedItems(tx, list.Id)
+		default:
+			respondWithJsonError(w, http.StatusBadRequest, errors.New(fmt.Sprintf("Unbekannter Wert `%s` für `state`.", state)))
+			return
+		}
+
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		respondWithJson(w, http.StatusOK, items)
+	case http.MethodPost:
+		idempotencyKey, err := apiIdempotencyKey(r)
+		if err != nil {
+			respondWithJsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		body := struct {
+			ListId    int     `json:"list_id"`
+			ProductId int     `json:"product_id"`
+			UnitId    int     `json:"unit_id"`
+			Quantity  float64 `json:"quantity"`
+			Note      string  `json:"note"`
+		}{}
+
+		err = decodeJsonBody(r, &body)
+		if err != nil {
+			respondWithJsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		list, err := apiList(r, body.ListId)
+		if err != nil {
+			respondWithJsonError(w, http.StatusNotFound, err)
+			return
+		}
+
+		product, err := env.queries.GetProduct(tx, user.HouseholdId, body.ProductId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithJsonError(w, http.StatusNotFound, errors.New("Produkt existiert nicht."))
+			} else {
+				respondWithJsonError(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		formErrors := make(map[string]string)
+		amount := strconv.FormatFloat(body.Quantity, 'f', -1, 64)
+
+		itemId, err := env.addItem(tx, user.Id, list.Id, product, strconv.Itoa(body.UnitId), amount, strings.TrimSpace(body.Note), formErrors)
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) != 0 {
+			respondWithJsonFormErrors(w, formErrors)
+			return
+		}
+
+		item, err := env.queries.GetItem(tx, user.HouseholdId, int(itemId))
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				respondWithJsonReplay(w)
+				return
+			} else {
+				respondWithJsonError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		env.events.publish(itemEvent{ListId: list.Id, ItemId: int(itemId), State: "added", UserId: user.Id})
+
+		respondWithJson(w, http.StatusCreated, item)
+	default:
+		respondWithJsonMethodNotAllowed(w, "GET, POST")
+	}
+}
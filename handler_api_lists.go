@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ApiListsRoute(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+		respondWithJson(w, http.StatusOK, lists)
+	case http.MethodPost:
+		idempotencyKey, err := apiIdempotencyKey(r)
+		if err != nil {
+			respondWithJsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		body := struct {
+			Name string `json:"name"`
+		}{}
+
+		err = decodeJsonBody(r, &body)
+		if err != nil {
+			respondWithJsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx, err := env.db.Begin()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+		defer tx.Rollback()
+
+		user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+		formErrors := make(map[string]string)
+		name := strings.TrimSpace(body.Name)
+
+		if name == "" {
+			formErrors["name"] = "Namen angeben"
+		} else if utf8.RuneCountInString(name) > 40 {
+			formErrors["name"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
+		}
+
+		if len(formErrors) != 0 {
+			respondWithJsonFormErrors(w, formErrors)
+			return
+		}
+
+		result, err := env.queries.InsertList(tx, user.HouseholdId, name)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: index 'idx_lists_name'" {
+				formErrors["name"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+				respondWithJsonFormErrors(w, formErrors)
+			} else {
+				respondWithJsonError(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		listId, err := result.LastInsertId()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				respondWithJsonReplay(w)
+				return
+			} else {
+				respondWithJsonError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		respondWithJson(w, http.StatusCreated, types.List{Id: int(listId), Name: name})
+	default:
+		respondWithJsonMethodNotAllowed(w, "GET, POST")
+	}
+}
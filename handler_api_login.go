@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+func (env *Environment) ApiLoginRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithJsonMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	body := struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}{}
+
+	err := decodeJsonBody(r, &body)
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, err := env.verifyLogin(tx, strings.TrimSpace(body.Email), body.Password)
+	if err != nil && !errors.Is(err, errInvalidCredentials) && !errors.Is(err, errTooManyFailedLogins) {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Commit in both cases so failed attempts are counted.
+	commitErr := tx.Commit()
+	if commitErr != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, commitErr)
+		return
+	}
+
+	if errors.Is(err, errTooManyFailedLogins) {
+		respondWithJsonError(w, http.StatusTooManyRequests, err)
+		return
+	} else if errors.Is(err, errInvalidCredentials) {
+		respondWithJsonFormErrors(w, map[string]string{"email": err.Error()})
+		return
+	}
+
+	env.session.Put(r, "household_id", user.HouseholdId)
+	env.session.Put(r, "user_id", user.Id)
+	respondWithJson(w, http.StatusOK, user)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ApiProductRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithJsonMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	productId, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	product, err := env.queries.GetProduct(tx, user.HouseholdId, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithJsonError(w, http.StatusNotFound, errors.New("Produkt existiert nicht."))
+		} else {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, product)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ApiProductsRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	switch r.Method {
+	case http.MethodGet:
+		var products []types.Product
+		if r.URL.Query().Get("q") != "" {
+			products, err = env.searchProducts(tx, user.HouseholdId, r.URL.Query().Get("q"))
+		} else {
+			products, err = env.queries.GetProducts(tx, user.HouseholdId)
+		}
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		respondWithJson(w, http.StatusOK, products)
+	case http.MethodPost:
+		idempotencyKey, err := apiIdempotencyKey(r)
+		if err != nil {
+			respondWithJsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		body := struct {
+			NameSingular string `json:"name_singular"`
+			NamePlural   string `json:"name_plural"`
+			CategoryIds  []int  `json:"category_ids"`
+			DimensionIds []int  `json:"dimension_ids"`
+		}{}
+
+		err = decodeJsonBody(r, &body)
+		if err != nil {
+			respondWithJsonError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		categoryIds := []string{}
+		for _, id := range body.CategoryIds {
+			categoryIds = append(categoryIds, strconv.Itoa(id))
+		}
+
+		dimensionIds := []string{}
+		for _, id := range body.DimensionIds {
+			dimensionIds = append(dimensionIds, strconv.Itoa(id))
+		}
+
+		formErrors := make(map[string]string)
+		nameSingular := strings.TrimSpace(body.NameSingular)
+		namePlural := strings.TrimSpace(body.NamePlural)
+
+		productId, err := env.addProduct(tx, user.HouseholdId, user.Id, nameSingular, namePlural, categoryIds, dimensionIds, formErrors)
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) != 0 {
+			respondWithJsonFormErrors(w, formErrors)
+			return
+		}
+
+		product, err := env.queries.GetProduct(tx, user.HouseholdId, int(productId))
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				respondWithJsonReplay(w)
+				return
+			} else {
+				respondWithJsonError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		respondWithJson(w, http.StatusCreated, product)
+	default:
+		respondWithJsonMethodNotAllowed(w, "GET, POST")
+	}
+}
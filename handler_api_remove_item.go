@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+)
+
+func (env *Environment) ApiRemoveItemRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithJsonMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	body := struct {
+		ItemId int `json:"item_id"`
+	}{}
+
+	err := decodeJsonBody(r, &body)
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	env.apiTransitionItem(w, r, body.ItemId, "added", "removed")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ApiSetQuantityRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithJsonMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	idempotencyKey, err := apiIdempotencyKey(r)
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body := struct {
+		ItemId   int     `json:"item_id"`
+		UnitId   int     `json:"unit_id"`
+		Quantity float64 `json:"quantity"`
+		Note     *string `json:"note"`
+	}{}
+
+	err = decodeJsonBody(r, &body)
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	item, err := env.queries.GetItem(tx, user.HouseholdId, body.ItemId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithJsonError(w, http.StatusNotFound, errors.New("Eintrag existiert nicht."))
+		} else {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	product, err := env.queries.GetProduct(tx, user.HouseholdId, item.ProductId)
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Clients which do not know about notes yet must not erase them.
+	note := item.Note
+	if body.Note != nil {
+		note = strings.TrimSpace(*body.Note)
+	}
+
+	formErrors := make(map[string]string)
+	amount := strconv.FormatFloat(body.Quantity, 'f', -1, 64)
+
+	err = env.setItemQuantity(tx, user.Id, item, product, strconv.Itoa(body.UnitId), amount, note, formErrors)
+	if err != nil {
+		if errors.Is(err, errWrongItemState) {
+			respondWithJsonError(w, http.StatusConflict, err)
+		} else {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if len(formErrors) != 0 {
+		respondWithJsonFormErrors(w, formErrors)
+		return
+	}
+
+	// Changing the dimension can merge the item into another one, that is why the result is the added item for the chosen dimension.
+	_, dimension, _ := findUnit(product, strconv.Itoa(body.UnitId))
+	changedItem, err := env.queries.GetAddedItemByProductDimension(tx, item.ListId, product.Id, dimension.Id)
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+	if err != nil {
+		if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+			respondWithJsonReplay(w)
+			return
+		} else {
+			respondWithJsonError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	env.events.publish(itemEvent{ListId: item.ListId, ItemId: changedItem.Id, State: "added", UserId: user.Id})
+
+	respondWithJson(w, http.StatusOK, changedItem)
+}
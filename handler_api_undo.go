@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+func (env *Environment) ApiUndoRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithJsonMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	body := struct {
+		ItemId   int    `json:"item_id"`
+		OldState string `json:"old_state"`
+	}{}
+
+	err := decodeJsonBody(r, &body)
+	if err != nil {
+		respondWithJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Only the "undo" transitions from docs/item-state-machine.txt are allowed here.
+	if body.OldState != "gathered" && body.OldState != "removed" {
+		respondWithJsonError(w, http.StatusBadRequest, errors.New(fmt.Sprintf("Unbekannter Wert `%s` für `old_state`.", body.OldState)))
+		return
+	}
+
+	env.apiTransitionItem(w, r, body.ItemId, body.OldState, "added")
+}
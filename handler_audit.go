@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+// AuditRoute lists the recorded item and product changes of the household, `format=csv` downloads them instead.
+func (env *Environment) AuditRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	query := r.URL.Query()
+	today := time.Now().Format("2006-01-02")
+
+	userId := 0
+	if query.Get("user-id") != "" {
+		userId, err = strconv.Atoi(query.Get("user-id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	productId := 0
+	if query.Get("product-id") != "" {
+		productId, err = strconv.Atoi(query.Get("product-id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	state := query.Get("state")
+	validState := false
+	for _, option := range auditStateOptions {
+		if option.Id == state {
+			validState = true
+		}
+	}
+	if !validState {
+		respondWithErrorPage(w, http.StatusBadRequest, errors.New("Unbekannte Art von Änderung."))
+		return
+	}
+
+	fromDate := query.Get("from")
+	if fromDate == "" {
+		fromDate = time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+	}
+
+	toDate := query.Get("to")
+	if toDate == "" {
+		toDate = today
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromDate, time.Local)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, errors.New("Datum muss im Format `JJJJ-MM-TT` angegeben werden."))
+		return
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", toDate, time.Local)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, errors.New("Datum muss im Format `JJJJ-MM-TT` angegeben werden."))
+		return
+	}
+
+	isCsv := query.Get("format") == "csv"
+	limit := maxAuditEntries
+	if isCsv {
+		limit = -1
+	}
+
+	// The end date is inclusive.
+	to = to.AddDate(0, 0, 1)
+
+	itemEntries := []types.AuditEntry{}
+	if state != "product" {
+		itemEntries, err = env.queries.GetItemAudit(tx, user.HouseholdId, userId, productId, state, from, to, limit)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	productEntries := []types.AuditEntry{}
+	if state == "" || state == "product" {
+		productEntries, err = env.queries.GetProductAudit(tx, user.HouseholdId, userId, productId, from, to, limit)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	entries := types.MergeAuditEntries(itemEntries, productEntries, limit)
+
+	users, err := env.queries.GetUsers(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	products, err := env.queries.GetProducts(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// This is here because the queries require a transaction even though this handler does not make any database changes.
+	err = tx.Commit()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if isCsv {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"besserliste-%s-%s.csv\"", fromDate, toDate))
+
+		err = writeAuditCsv(w, entries)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	userOptions := []FormOption{}
+	for _, option := range users {
+		userOptions = append(userOptions, FormOption{
+			Id:   strconv.Itoa(option.Id),
+			Name: option.Name,
+		})
+	}
+
+	productOptions := []FormOption{}
+	for _, option := range products {
+		productOptions = append(productOptions, FormOption{
+			Id:   strconv.Itoa(option.Id),
+			Name: option.NamePlural,
+		})
+	}
+
+	csvQuery := url.Values{}
+	for key, values := range query {
+		csvQuery[key] = values
+	}
+	csvQuery.Set("from", fromDate)
+	csvQuery.Set("to", toDate)
+	csvQuery.Set("format", "csv")
+
+	data := struct {
+		CurrentUser    types.User
+		CurrentList    types.List
+		Lists          []types.List
+		UserOptions    []FormOption
+		ProductOptions []FormOption
+		StateOptions   []FormOption
+		UserId         string
+		ProductId      string
+		State          string
+		From           string
+		To             string
+		Entries        []types.AuditEntry
+		IsTruncated    bool
+		MaxEntries     int
+		CsvPath        string
+	}{
+		CurrentUser:    user,
+		CurrentList:    list,
+		Lists:          lists,
+		UserOptions:    userOptions,
+		ProductOptions: productOptions,
+		StateOptions:   auditStateOptions,
+		UserId:         query.Get("user-id"),
+		ProductId:      query.Get("product-id"),
+		State:          state,
+		From:           fromDate,
+		To:             toDate,
+		Entries:        entries,
+		IsTruncated:    len(entries) == maxAuditEntries,
+		MaxEntries:     maxAuditEntries,
+		CsvPath:        "/audit?" + csvQuery.Encode(),
+	}
+
+	files := []string{
+		"screens/audit.html",
+		"layouts/internal.html",
+	}
+
+	ts, err := template.ParseFS(web.Templates, files...)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+func (env *Environment) CategoriesRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	categories, err := env.queries.GetCategories(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// This is here because the queries require a transaction even though this handler does not make any database changes.
+	err = tx.Commit()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := struct {
+		CurrentUser    types.User
+		CurrentList    types.List
+		Lists          []types.List
+		Categories     []types.Category
+		IdempotencyKey string
+	}{
+		CurrentUser:    user,
+		CurrentList:    list,
+		Lists:          lists,
+		Categories:     categories,
+		IdempotencyKey: IdempotencyKey(),
+	}
+
+	files := []string{
+		"screens/categories.html",
+		"layouts/internal.html",
+	}
+
+	ts, err := template.ParseFS(web.Templates, files...)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+}
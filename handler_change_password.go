@@ -0,0 +1,119 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+func (env *Environment) ChangePasswordRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	renderForm := func(idempotencyKey string, formErrors map[string]string) {
+		files := []string{
+			"screens/change_password.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			IdempotencyKey string
+			FormErrors     map[string]string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		currentPassword := r.PostForm.Get("current_password")
+		newPassword := r.PostForm.Get("new_password")
+		newPasswordConfirmation := r.PostForm.Get("new_password_confirmation")
+
+		matches, err := env.passwordMatches(tx, user.Id, currentPassword)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !matches {
+			formErrors["current_password"] = "Passwort inkorrekt"
+		}
+
+		validateNewPassword(newPassword, newPasswordConfirmation, formErrors)
+
+		if len(formErrors) != 0 {
+			renderForm(idempotencyKey, formErrors)
+			return
+		}
+
+		err = env.setPassword(tx, user.Id, newPassword)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, "/home", http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/home", http.StatusSeeOther)
+	} else {
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm(IdempotencyKey(), make(map[string]string))
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
-	"stravid.com/besserliste/types"
 	"strconv"
+	"strings"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
 )
 
 func (env *Environment) CheckItemRoute(w http.ResponseWriter, r *http.Request) {
@@ -23,10 +28,66 @@ func (env *Environment) CheckItemRoute(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	renderForm := func(item *types.SelectedItem, sortBy string, price string, priceUnitId string, idempotencyKey string, formErrors map[string]string) {
+		unitOptions := []FormOption{}
+		for _, unit := range item.Dimension.Units {
+			unitOptions = append(unitOptions, FormOption{
+				Id:   strconv.Itoa(unit.Id),
+				Name: unit.NameSingular,
+			})
+		}
+
+		files := []string{
+			"screens/check_item.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			Item           *types.SelectedItem
+			UnitOptions    []FormOption
+			SortBy         string
+			IdempotencyKey string
+			FormErrors     map[string]string
+			Price          string
+			PriceUnitId    string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			Item:           item,
+			UnitOptions:    unitOptions,
+			SortBy:         sortBy,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+			Price:          price,
+			PriceUnitId:    priceUnitId,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
 
 	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
 		idempotencyKey := r.PostForm.Get("_idempotency_key")
 		sortBy := r.PostForm.Get("sort_by")
+		price := strings.TrimSpace(r.PostForm.Get("price"))
+		priceUnitId := r.PostForm.Get("price_unit_id")
 		itemId, err := strconv.Atoi(r.PostForm.Get("item_id"))
 		if err != nil {
 			respondWithErrorPage(w, http.StatusBadRequest, err)
@@ -38,47 +99,82 @@ func (env *Environment) CheckItemRoute(w http.ResponseWriter, r *http.Request) {
 			successPath = "/shop"
 		}
 
-		item, err := env.queries.GetItem(tx, itemId)
+		item, err := env.transitionItem(tx, user.HouseholdId, user.Id, itemId, "added", "gathered")
 		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			if errors.Is(err, errWrongItemState) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
 			return
 		}
 
-		if item.State != "added" {
-			respondWithErrorPage(w, http.StatusBadRequest, errors.New("Eintrag befindet sich im falschen Zustand."))
-			return
+		// The price is optional, checking off from the shopping list does not send one.
+		if price != "" {
+			parsedPrice := itemPrice(item, price, priceUnitId, formErrors)
+			if len(formErrors) != 0 {
+				tx.Rollback()
+				renderForm(item, sortBy, price, priceUnitId, idempotencyKey, formErrors)
+				return
+			}
+
+			err = env.queries.SetLatestItemChangePrice(tx, item.Id, parsedPrice)
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
 		}
 
-		err = env.queries.SetItemState(tx, item.Id, "gathered")
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, successPath, http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
 		if err != nil {
 			respondWithErrorPage(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		err = env.queries.InsertItemChange(tx, int64(item.Id), user.Id, item.Dimension.Id, int64(item.Quantity), "gathered")
+		env.events.publish(itemEvent{ListId: item.ListId, ItemId: item.Id, State: item.State, UserId: user.Id})
+
+		http.Redirect(w, r, successPath, http.StatusSeeOther)
+	} else if r.Form.Get("item-id") != "" {
+		itemId, err := strconv.Atoi(r.Form.Get("item-id"))
 		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			respondWithErrorPage(w, http.StatusBadRequest, err)
 			return
 		}
 
-		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		item, err := env.queries.GetItem(tx, user.HouseholdId, itemId)
 		if err != nil {
-			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
-				http.Redirect(w, r, successPath, http.StatusSeeOther)
-				return
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithErrorPage(w, http.StatusNotFound, err)
 			} else {
 				respondWithErrorPage(w, http.StatusInternalServerError, err)
-				return
 			}
+			return
 		}
 
+		if item.State != "added" {
+			respondWithErrorPage(w, http.StatusBadRequest, errWrongItemState)
+			return
+		}
+
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
 		err = tx.Commit()
 		if err != nil {
 			respondWithErrorPage(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		http.Redirect(w, r, successPath, http.StatusSeeOther)
+		renderForm(item, r.Form.Get("sort-by"), "", "", IdempotencyKey(), make(map[string]string))
 	} else {
 		err = tx.Commit()
 		if err != nil {
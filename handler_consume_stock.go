@@ -0,0 +1,145 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+)
+
+func (env *Environment) ConsumeStockRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stockLevelId, err := strconv.Atoi(r.Form.Get("stock-level-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stockLevel, err := env.queries.GetStockLevel(tx, user.HouseholdId, stockLevelId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	unitOptions := []FormOption{}
+	for _, unit := range stockLevel.Dimension.Units {
+		unitOptions = append(unitOptions, FormOption{
+			Id:   strconv.Itoa(unit.Id),
+			Name: unit.NamePlural,
+		})
+	}
+
+	renderForm := func(quantity string, unitId string, idempotencyKey string, formErrors map[string]string) {
+		files := []string{
+			"screens/consume_stock.html",
+			"layouts/internal.html",
+		}
+
+		data := struct {
+			CurrentUser    types.User
+			CurrentList    types.List
+			Lists          []types.List
+			StockLevel     *types.StockLevel
+			UnitOptions    []FormOption
+			IdempotencyKey string
+			FormErrors     map[string]string
+			Quantity       string
+			UnitId         string
+		}{
+			CurrentUser:    user,
+			CurrentList:    list,
+			Lists:          lists,
+			StockLevel:     stockLevel,
+			UnitOptions:    unitOptions,
+			Quantity:       quantity,
+			UnitId:         unitId,
+			IdempotencyKey: idempotencyKey,
+			FormErrors:     formErrors,
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		unitId := r.PostForm.Get("unit_id")
+		amount := r.PostForm.Get("quantity")
+
+		events, err := env.consumeStock(tx, user.HouseholdId, user.Id, stockLevel, unitId, amount, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/pantry", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			for _, event := range events {
+				env.events.publish(event)
+			}
+
+			http.Redirect(w, r, "/pantry", http.StatusSeeOther)
+		} else {
+			renderForm(amount, unitId, idempotencyKey, formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		unit := types.BestFittingUnit(stockLevel.Quantity, stockLevel.Dimension.Units)
+		renderForm("", strconv.Itoa(unit.Id), IdempotencyKey(), make(map[string]string))
+	}
+}
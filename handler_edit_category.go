@@ -0,0 +1,144 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+	"strings"
+)
+
+func (env *Environment) EditCategoryRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	categoryId, err := strconv.Atoi(r.Form.Get("category-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	category, err := env.queries.GetCategory(tx, user.HouseholdId, categoryId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	exclusiveProducts, err := env.queries.CountExclusiveCategoryProducts(tx, category.Id)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	renderForm := func(name string, idempotencyKey string, formErrors map[string]string) {
+		data := struct {
+			CurrentUser       types.User
+			CurrentList       types.List
+			Lists             []types.List
+			Category          *types.Category
+			ExclusiveProducts int
+			Name              string
+			IdempotencyKey    string
+			RemoveKey         string
+			FormErrors        map[string]string
+		}{
+			CurrentUser:       user,
+			CurrentList:       list,
+			Lists:             lists,
+			Category:          category,
+			ExclusiveProducts: exclusiveProducts,
+			Name:              name,
+			IdempotencyKey:    idempotencyKey,
+			RemoveKey:         IdempotencyKey(),
+			FormErrors:        formErrors,
+		}
+
+		files := []string{
+			"screens/edit_category.html",
+			"layouts/internal.html",
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		name := strings.TrimSpace(r.PostForm.Get("name"))
+
+		err = env.renameCategory(tx, user.HouseholdId, category.Id, name, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/categories", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, "/categories", http.StatusSeeOther)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			renderForm(name, idempotencyKey, formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm(category.Name, IdempotencyKey(), make(map[string]string))
+	}
+}
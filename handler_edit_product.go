@@ -0,0 +1,216 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+	"strings"
+)
+
+func (env *Environment) EditProductRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	productId, err := strconv.Atoi(r.Form.Get("product-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := env.queries.GetProductDetails(tx, user.HouseholdId, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	usage, err := env.queries.CountProductUsage(tx, product.Id)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	aliases, err := env.queries.GetProductAliases(tx, product.Id)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	categories, err := env.queries.GetCategories(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dimensions, err := env.queries.GetDimensions(tx)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	categoryOptions := []FormOption{}
+	for _, category := range categories {
+		categoryOptions = append(categoryOptions, FormOption{
+			Id:   strconv.Itoa(category.Id),
+			Name: category.Name,
+		})
+	}
+
+	dimensionOptions := []FormOption{}
+	for _, dimension := range dimensions {
+		dimensionOptions = append(dimensionOptions, FormOption{
+			Id:   strconv.Itoa(dimension.Id),
+			Name: dimension.Name,
+		})
+	}
+
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	renderForm := func(nameSingular string, namePlural string, categoryIds map[string]bool, dimensionIds map[string]bool, idempotencyKey string, formErrors map[string]string) {
+		data := struct {
+			CurrentUser      types.User
+			CurrentList      types.List
+			Lists            []types.List
+			Product          *types.ProductDetails
+			IsUsed           bool
+			Aliases          []types.ProductAlias
+			Categories       []FormOption
+			DimensionOptions []FormOption
+			NameSingular     string
+			NamePlural       string
+			CategoryIds      map[string]bool
+			DimensionIds     map[string]bool
+			IdempotencyKey   string
+			RemoveKey        string
+			RemoveAliasKey   string
+			FormErrors       map[string]string
+		}{
+			CurrentUser:      user,
+			CurrentList:      list,
+			Lists:            lists,
+			Product:          product,
+			IsUsed:           usage != 0,
+			Aliases:          aliases,
+			Categories:       categoryOptions,
+			DimensionOptions: dimensionOptions,
+			NameSingular:     nameSingular,
+			NamePlural:       namePlural,
+			CategoryIds:      categoryIds,
+			DimensionIds:     dimensionIds,
+			IdempotencyKey:   idempotencyKey,
+			RemoveKey:        IdempotencyKey(),
+			RemoveAliasKey:   IdempotencyKey(),
+			FormErrors:       formErrors,
+		}
+
+		files := []string{
+			"screens/edit_product.html",
+			"layouts/internal.html",
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		nameSingular := strings.TrimSpace(r.PostForm.Get("name_singular"))
+		namePlural := strings.TrimSpace(r.PostForm.Get("name_plural"))
+		categoryIds := r.PostForm["category_ids"]
+		dimensionIds := r.PostForm["dimension_ids"]
+
+		selectedDimensions := map[string]bool{}
+		for _, id := range dimensionIds {
+			selectedDimensions[id] = true
+		}
+
+		selectedCategories := map[string]bool{}
+		for _, id := range categoryIds {
+			selectedCategories[id] = true
+		}
+
+		err = env.editProduct(tx, user.HouseholdId, user.Id, product.Id, nameSingular, namePlural, categoryIds, dimensionIds, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/products", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, "/products", http.StatusSeeOther)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			renderForm(nameSingular, namePlural, selectedCategories, selectedDimensions, idempotencyKey, formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		selectedCategories := map[string]bool{}
+		for _, id := range product.CategoryIds {
+			selectedCategories[strconv.Itoa(id)] = true
+		}
+
+		selectedDimensions := map[string]bool{}
+		for _, id := range product.DimensionIds {
+			selectedDimensions[strconv.Itoa(id)] = true
+		}
+
+		renderForm(product.NameSingular, product.NamePlural, selectedCategories, selectedDimensions, IdempotencyKey(), make(map[string]string))
+	}
+}
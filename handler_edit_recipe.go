@@ -0,0 +1,149 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+	"strings"
+)
+
+func (env *Environment) EditRecipeRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	recipeId, err := strconv.Atoi(r.Form.Get("recipe-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	recipe, err := env.queries.GetRecipe(tx, user.HouseholdId, recipeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ingredients, err := env.queries.GetRecipeIngredients(tx, recipe.Id)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	renderForm := func(name string, servings string, idempotencyKey string, formErrors map[string]string) {
+		data := struct {
+			CurrentUser         types.User
+			CurrentList         types.List
+			Lists               []types.List
+			Recipe              *types.Recipe
+			Ingredients         []types.RecipeIngredient
+			Name                string
+			Servings            string
+			IdempotencyKey      string
+			RemoveIngredientKey string
+			RemoveKey           string
+			FormErrors          map[string]string
+		}{
+			CurrentUser:         user,
+			CurrentList:         list,
+			Lists:               lists,
+			Recipe:              recipe,
+			Ingredients:         ingredients,
+			Name:                name,
+			Servings:            servings,
+			IdempotencyKey:      idempotencyKey,
+			RemoveIngredientKey: IdempotencyKey(),
+			RemoveKey:           IdempotencyKey(),
+			FormErrors:          formErrors,
+		}
+
+		files := []string{
+			"screens/edit_recipe.html",
+			"layouts/internal.html",
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		name := strings.TrimSpace(r.PostForm.Get("name"))
+		servings := strings.TrimSpace(r.PostForm.Get("servings"))
+
+		err = env.editRecipe(tx, user.HouseholdId, recipe.Id, name, servings, formErrors)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(formErrors) == 0 {
+			err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+			if err != nil {
+				if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+					http.Redirect(w, r, "/recipes", http.StatusSeeOther)
+					return
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, "/recipes", http.StatusSeeOther)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			renderForm(name, servings, idempotencyKey, formErrors)
+		}
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm(recipe.Name, strconv.Itoa(recipe.Servings), IdempotencyKey(), make(map[string]string))
+	}
+}
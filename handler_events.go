@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"stravid.com/besserliste/types"
+)
+
+// EventsRoute streams the item changes of the current list as Server-Sent Events.
+// The session middleware buffers the whole response, so only authentication runs inside it and the stream is written to the connection afterwards.
+func (env *Environment) EventsRoute(w http.ResponseWriter, r *http.Request) {
+	authenticated := false
+	list := types.List{}
+
+	authenticate := env.session.Enable(env.authenticate(env.requireAuthentication(env.selectList(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = true
+		list, _ = r.Context().Value(contextKeyCurrentList).(types.List)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	})))))
+	authenticate.ServeHTTP(w, r)
+
+	if !authenticated {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Println(errors.New("EventsRoute: response writer does not support flushing"))
+		return
+	}
+
+	events := env.events.subscribe(list.Id)
+	defer env.events.unsubscribe(events)
+
+	// Ask browsers to wait a bit longer than the default before reconnecting.
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Println(err.Error())
+				return
+			}
+
+			fmt.Fprintf(w, "event: item\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+// Purchases of the same user belong to one trip unless there is a longer break in between.
+const maxTripGap = 60 * time.Minute
+
+func (env *Environment) HistoryRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	dates, err := env.queries.GetPurchaseDates(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+	if date == "" && len(dates) > 0 {
+		date = dates[0]
+	}
+
+	trips := []types.Trip{}
+	formattedDate := ""
+	previousDate := ""
+	nextDate := ""
+
+	if date != "" {
+		day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, errors.New("Datum muss im Format `JJJJ-MM-TT` angegeben werden."))
+			return
+		}
+
+		purchases, err := env.queries.GetPurchases(tx, user.HouseholdId, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		trips = types.GroupTrips(purchases, maxTripGap)
+		formattedDate = day.Format("02.01.2006")
+
+		// Dates are ordered with the most recent one first.
+		for _, d := range dates {
+			if d > date {
+				nextDate = d
+			}
+			if d < date && previousDate == "" {
+				previousDate = d
+			}
+		}
+	}
+
+	// This is here because the queries require a transaction even though this handler does not make any database changes.
+	err = tx.Commit()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dateOptions := []FormOption{}
+	for _, d := range dates {
+		day, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dateOptions = append(dateOptions, FormOption{
+			Id:   d,
+			Name: day.Format("02.01.2006"),
+		})
+	}
+
+	data := struct {
+		CurrentUser   types.User
+		CurrentList   types.List
+		Lists         []types.List
+		Date          string
+		FormattedDate string
+		PreviousDate  string
+		NextDate      string
+		DateOptions   []FormOption
+		Trips         []types.Trip
+	}{
+		CurrentUser:   user,
+		CurrentList:   list,
+		Lists:         lists,
+		Date:          date,
+		FormattedDate: formattedDate,
+		PreviousDate:  previousDate,
+		NextDate:      nextDate,
+		DateOptions:   dateOptions,
+		Trips:         trips,
+	}
+
+	files := []string{
+		"screens/history.html",
+		"layouts/internal.html",
+	}
+
+	ts, err := template.ParseFS(web.Templates, files...)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+}
@@ -9,10 +9,16 @@ import (
 
 func (env *Environment) HomeRoute(w http.ResponseWriter, r *http.Request) {
 	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
 	data := struct {
 		CurrentUser types.User
+		CurrentList types.List
+		Lists       []types.List
 	}{
 		CurrentUser: user,
+		CurrentList: list,
+		Lists:       lists,
 	}
 
 	files := []string{
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"html/template"
 	"net/http"
+	"stravid.com/besserliste/types"
 	"stravid.com/besserliste/web"
-	"strconv"
+	"strings"
 )
 
 func (env *Environment) LoginRoute(w http.ResponseWriter, r *http.Request) {
@@ -21,38 +23,13 @@ func (env *Environment) LoginRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := env.queries.GetUsers(tx)
-	if err != nil {
-		respondWithErrorPage(w, http.StatusInternalServerError, err)
-		return
-	}
-
-	// This is here because the queries require a transaction even though this handler does not make any database changes.
-	err = tx.Commit()
-	if err != nil {
-		respondWithErrorPage(w, http.StatusInternalServerError, err)
-		return
-	}
-
-	userOptions := []FormOption{}
-	userSet := map[string]bool{}
-	for _, user := range users {
-		userSet[strconv.Itoa(user.Id)] = true
-		userOptions = append(userOptions, FormOption{
-			Id:   strconv.Itoa(user.Id),
-			Name: user.Name,
-		})
-	}
-
-	renderForm := func(userId string, idempotencyKey string, formErrors map[string]string) {
+	renderForm := func(email string, idempotencyKey string, formErrors map[string]string) {
 		data := struct {
-			UserOptions    []FormOption
-			UserId         string
+			Email          string
 			IdempotencyKey string
 			FormErrors     map[string]string
 		}{
-			UserOptions:    userOptions,
-			UserId:         userId,
+			Email:          email,
 			IdempotencyKey: idempotencyKey,
 			FormErrors:     formErrors,
 		}
@@ -78,24 +55,40 @@ func (env *Environment) LoginRoute(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		formErrors := make(map[string]string)
 		idempotencyKey := r.PostForm.Get("_idempotency_key")
-		userId := r.PostForm.Get("user_id")
+		email := strings.TrimSpace(r.PostForm.Get("email"))
 		password := r.PostForm.Get("password")
 
-		if !userSet[userId] {
-			formErrors["user_id"] = "Benutzer wählen"
+		if email == "" {
+			formErrors["email"] = "E-Mail-Adresse angeben"
 		}
 
-		if password != env.password {
-			formErrors["password"] = "Passwort inkorrekt"
+		var user *types.User
+		if len(formErrors) == 0 {
+			user, err = env.verifyLogin(tx, email, password)
+			if err != nil {
+				if errors.Is(err, errInvalidCredentials) || errors.Is(err, errTooManyFailedLogins) {
+					formErrors["email"] = err.Error()
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			// Commit in both cases so failed attempts are counted.
+			err = tx.Commit()
+			if err != nil {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
 		}
 
 		if len(formErrors) == 0 {
-			intUserId, _ := strconv.Atoi(userId)
-			env.session.Put(r, "user_id", intUserId)
+			env.session.Put(r, "household_id", user.HouseholdId)
+			env.session.Put(r, "user_id", user.Id)
 
 			http.Redirect(w, r, "/plan", http.StatusSeeOther)
 		} else {
-			renderForm(userId, idempotencyKey, formErrors)
+			renderForm(email, idempotencyKey, formErrors)
 		}
 	} else {
 		renderForm("", IdempotencyKey(), make(map[string]string))
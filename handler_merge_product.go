@@ -0,0 +1,171 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+	"strconv"
+)
+
+func (env *Environment) MergeProductRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		// Replays have to be detected before looking up the product, a successful merge deleted it.
+		err = env.queries.InsertIdempotencyKey(tx, r.PostForm.Get("_idempotency_key"))
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, "/products", http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+
+	productId, err := strconv.Atoi(r.Form.Get("product-id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := env.queries.GetProductDetails(tx, user.HouseholdId, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithErrorPage(w, http.StatusNotFound, err)
+		} else {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	products, err := env.queries.GetProducts(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	productOptions := []FormOption{}
+	for _, option := range products {
+		if option.Id != product.Id {
+			productOptions = append(productOptions, FormOption{
+				Id:   strconv.Itoa(option.Id),
+				Name: option.NamePlural,
+			})
+		}
+	}
+
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	renderForm := func(survivingProductId string, formErrors map[string]string) {
+		data := struct {
+			CurrentUser        types.User
+			CurrentList        types.List
+			Lists              []types.List
+			Product            *types.ProductDetails
+			ProductOptions     []FormOption
+			SurvivingProductId string
+			IdempotencyKey     string
+			FormErrors         map[string]string
+		}{
+			CurrentUser:        user,
+			CurrentList:        list,
+			Lists:              lists,
+			Product:            product,
+			ProductOptions:     productOptions,
+			SurvivingProductId: survivingProductId,
+			IdempotencyKey:     IdempotencyKey(),
+			FormErrors:         formErrors,
+		}
+
+		files := []string{
+			"screens/merge_product.html",
+			"layouts/internal.html",
+		}
+
+		ts, err := template.ParseFS(web.Templates, files...)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = ts.Execute(w, data)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		formErrors := make(map[string]string)
+		survivingProductId := r.PostForm.Get("surviving_product_id")
+
+		var survivingProduct *types.ProductDetails
+		id, err := strconv.Atoi(survivingProductId)
+		if err != nil || id == product.Id {
+			formErrors["surviving_product_id"] = "Produkt wählen"
+		} else {
+			survivingProduct, err = env.queries.GetProductDetails(tx, user.HouseholdId, id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					formErrors["surviving_product_id"] = "Produkt wählen"
+				} else {
+					respondWithErrorPage(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+		}
+
+		if len(formErrors) != 0 {
+			// Nothing happened, rolling back also forgets the idempotency key so the corrected form can be sent.
+			tx.Rollback()
+			renderForm(survivingProductId, formErrors)
+			return
+		}
+
+		events, err := env.mergeProducts(tx, user.HouseholdId, user.Id, product, survivingProduct)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, event := range events {
+			env.events.publish(event)
+		}
+
+		http.Redirect(w, r, "/products", http.StatusSeeOther)
+	} else {
+		// This is here because the queries require a transaction even though this handler does not make any database changes.
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		renderForm("", make(map[string]string))
+	}
+}
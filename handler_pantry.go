@@ -0,0 +1,64 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+func (env *Environment) PantryRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	stockLevels, err := env.queries.GetStockLevels(tx, user.HouseholdId)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// This is here because the queries require a transaction even though this handler does not make any database changes.
+	err = tx.Commit()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := struct {
+		CurrentUser types.User
+		CurrentList types.List
+		Lists       []types.List
+		StockLevels []types.StockLevel
+	}{
+		CurrentUser: user,
+		CurrentList: list,
+		Lists:       lists,
+		StockLevels: stockLevels,
+	}
+
+	files := []string{
+		"screens/pantry.html",
+		"layouts/internal.html",
+	}
+
+	ts, err := template.ParseFS(web.Templates, files...)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"net/http"
 	"stravid.com/besserliste/types"
 	"stravid.com/besserliste/web"
+	"time"
 )
 
+// Enough to jog the memory without pushing the list itself off the screen.
+const maxSuggestions = 8
+
 func (env *Environment) PlanRoute(w http.ResponseWriter, r *http.Request) {
 	tx, err := env.db.Begin()
 	if err != nil {
@@ -15,19 +19,23 @@ func (env *Environment) PlanRoute(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
-	addedItems, err := env.queries.GetAddedItems(tx)
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	addedItems, err := env.queries.GetAddedItems(tx, list.Id)
 	if err != nil {
 		respondWithErrorPage(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	removedItems, err := env.queries.GetRemovedItems(tx)
+	removedItems, err := env.queries.GetRemovedItems(tx, list.Id)
 	if err != nil {
 		respondWithErrorPage(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	products, err := env.queries.GetProducts(tx)
+	purchases, err := env.queries.GetPurchases(tx, user.HouseholdId, time.Now().AddDate(-1, 0, 0), time.Now())
 	if err != nil {
 		respondWithErrorPage(w, http.StatusInternalServerError, err)
 		return
@@ -39,23 +47,45 @@ func (env *Environment) PlanRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Products already on the list are not running out anymore.
+	addedProductIds := map[int]bool{}
+	for _, item := range addedItems {
+		addedProductIds[item.ProductId] = true
+	}
+
+	suggestions := types.SuggestPurchases(purchases, time.Now(), addedProductIds)
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	// Every suggestion is its own form, adding one must not look like a replay of another.
+	suggestionKeys := map[int]string{}
+	for _, suggestion := range suggestions {
+		suggestionKeys[suggestion.ProductId] = IdempotencyKey()
+	}
+
 	files := []string{
 		"screens/plan.html",
 		"layouts/internal.html",
 	}
 
-	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
 	data := struct {
 		CurrentUser    types.User
-		Products       []types.Product
+		CurrentList    types.List
+		Lists          []types.List
 		AddedItems     []types.AddedItem
 		RemovedItems   []types.AddedItem
+		Suggestions    []types.Suggestion
+		SuggestionKeys map[int]string
 		IdempotencyKey string
 	}{
 		CurrentUser:    user,
-		Products:       products,
+		CurrentList:    list,
+		Lists:          lists,
 		AddedItems:     addedItems,
 		RemovedItems:   removedItems,
+		Suggestions:    suggestions,
+		SuggestionKeys: suggestionKeys,
 		IdempotencyKey: IdempotencyKey(),
 	}
 
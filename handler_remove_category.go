@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"strconv"
+)
+
+func (env *Environment) RemoveCategoryRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	if r.Method == http.MethodPost {
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		categoryId, err := strconv.Atoi(r.PostForm.Get("category_id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		err = env.removeCategory(tx, user.HouseholdId, categoryId)
+		if err != nil {
+			if errors.Is(err, errLastCategory) || errors.Is(err, errCategoryInUse) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else if errors.Is(err, sql.ErrNoRows) {
+				respondWithErrorPage(w, http.StatusNotFound, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, "/categories", http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/categories", http.StatusSeeOther)
+}
@@ -31,26 +31,13 @@ func (env *Environment) RemoveItemRoute(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		item, err := env.queries.GetItem(tx, itemId)
+		item, err := env.transitionItem(tx, user.HouseholdId, user.Id, itemId, "added", "removed")
 		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		if item.State != "added" {
-			respondWithErrorPage(w, http.StatusBadRequest, errors.New("Eintrag befindet sich im falschen Zustand."))
-			return
-		}
-
-		err = env.queries.SetItemState(tx, item.Id, "removed")
-		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		err = env.queries.InsertItemChange(tx, int64(item.Id), user.Id, item.Dimension.Id, int64(item.Quantity), "removed")
-		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			if errors.Is(err, errWrongItemState) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
 			return
 		}
 
@@ -71,6 +58,8 @@ func (env *Environment) RemoveItemRoute(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		env.events.publish(itemEvent{ListId: item.ListId, ItemId: item.Id, State: item.State, UserId: user.Id})
+
 		http.Redirect(w, r, "/plan", http.StatusSeeOther)
 	} else {
 		err = tx.Commit()
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"strconv"
+)
+
+func (env *Environment) RemoveProductRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	if r.Method == http.MethodPost {
+		// Replays have to be detected before looking up the product, a successful removal deleted it.
+		err = env.queries.InsertIdempotencyKey(tx, r.PostForm.Get("_idempotency_key"))
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, "/products", http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		productId, err := strconv.Atoi(r.PostForm.Get("product_id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		product, err := env.queries.GetProductDetails(tx, user.HouseholdId, productId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithErrorPage(w, http.StatusNotFound, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		err = env.removeProduct(tx, user.HouseholdId, product)
+		if err != nil {
+			if errors.Is(err, errProductInUse) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/products", http.StatusSeeOther)
+}
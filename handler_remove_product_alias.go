@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) RemoveProductAliasRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	successPath := "/products"
+
+	if r.Method == http.MethodPost {
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		productId, err := strconv.Atoi(r.PostForm.Get("product_id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		aliasId, err := strconv.Atoi(r.PostForm.Get("alias_id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		successPath = fmt.Sprintf("/edit-product?product-id=%d", productId)
+
+		err = env.removeProductAlias(tx, user.HouseholdId, aliasId)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, successPath, http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, successPath, http.StatusSeeOther)
+}
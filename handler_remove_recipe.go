@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"stravid.com/besserliste/types"
+	"strconv"
+)
+
+func (env *Environment) RemoveRecipeRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	if r.Method == http.MethodPost {
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		recipeId, err := strconv.Atoi(r.PostForm.Get("recipe_id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		err = env.removeRecipe(tx, user.HouseholdId, recipeId)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, "/recipes", http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/recipes", http.StatusSeeOther)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) ReorderCategoriesRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	if r.Method == http.MethodPost {
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		categoryIds := r.PostForm["category_ids"]
+
+		if id := r.PostForm.Get("move_up"); id != "" {
+			categoryIds = moveCategory(categoryIds, id, -1)
+		} else if id := r.PostForm.Get("move_down"); id != "" {
+			categoryIds = moveCategory(categoryIds, id, 1)
+		}
+
+		err = env.reorderCategories(tx, user.HouseholdId, categoryIds)
+		if err != nil {
+			if errors.Is(err, errCategoriesChanged) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, "/categories", http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/categories", http.StatusSeeOther)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"stravid.com/besserliste/types"
+	"strconv"
+)
+
+func (env *Environment) ReorderStoreRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+	if r.Method == http.MethodPost {
+		idempotencyKey := r.PostForm.Get("_idempotency_key")
+		storeId, err := strconv.Atoi(r.PostForm.Get("store_id"))
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, err)
+			return
+		}
+
+		successPath := fmt.Sprintf("/edit-store?store-id=%d", storeId)
+		categoryIds := r.PostForm["category_ids"]
+
+		if id := r.PostForm.Get("move_up"); id != "" {
+			categoryIds = moveCategory(categoryIds, id, -1)
+		} else if id := r.PostForm.Get("move_down"); id != "" {
+			categoryIds = moveCategory(categoryIds, id, 1)
+		}
+
+		err = env.reorderStoreCategories(tx, user.HouseholdId, storeId, categoryIds)
+		if err != nil {
+			if errors.Is(err, errStoreNotFound) {
+				respondWithErrorPage(w, http.StatusNotFound, err)
+			} else if errors.Is(err, errCategoriesChanged) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		err = env.queries.InsertIdempotencyKey(tx, idempotencyKey)
+		if err != nil {
+			if err.Error() == "UNIQUE constraint failed: idempotency_keys.key" {
+				http.Redirect(w, r, successPath, http.StatusSeeOther)
+				return
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, successPath, http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/stores", http.StatusSeeOther)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"stravid.com/besserliste/types"
+)
+
+func (env *Environment) SelectListRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithErrorPage(w, http.StatusBadRequest, errors.New("Liste muss per `POST` Methode gewechselt werden."))
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	listId, err := strconv.Atoi(r.PostForm.Get("list_id"))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusBadRequest, err)
+		return
+	}
+
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+	for _, list := range lists {
+		if list.Id == listId {
+			env.session.Put(r, "list_id", list.Id)
+
+			// Stay on the shopping screen when switching lists while shopping.
+			referer, err := url.Parse(r.Referer())
+			if err == nil && referer.Path == "/shop" {
+				http.Redirect(w, r, "/shop", http.StatusSeeOther)
+			} else {
+				http.Redirect(w, r, "/plan", http.StatusSeeOther)
+			}
+			return
+		}
+	}
+
+	respondWithErrorPage(w, http.StatusBadRequest, errors.New("Liste existiert nicht."))
+}
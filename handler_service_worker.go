@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/http"
+
+	"stravid.com/besserliste/web"
+)
+
+// ServiceWorkerRoute serves the service worker from the root so its scope covers the whole application and not only `/static/`.
+func (env *Environment) ServiceWorkerRoute(w http.ResponseWriter, r *http.Request) {
+	script, err := web.Static.ReadFile("static/service-worker.js")
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	// Browsers check for a new version on every navigation, this makes sure they actually get it.
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(script)
+}
@@ -1,13 +1,13 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"stravid.com/besserliste/types"
 	"stravid.com/besserliste/web"
 	"strconv"
+	"strings"
 )
 
 func (env *Environment) ShopRoute(w http.ResponseWriter, r *http.Request) {
@@ -18,6 +18,10 @@ func (env *Environment) ShopRoute(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
 	err = r.ParseForm()
 	if err != nil {
 		respondWithErrorPage(w, http.StatusBadRequest, err)
@@ -26,43 +30,48 @@ func (env *Environment) ShopRoute(w http.ResponseWriter, r *http.Request) {
 
 	sortBy := r.Form.Get("sort-by")
 	sortSet := map[string]bool{
-		"": true,
+		"":           true,
+		"categories": true,
 	}
 	sortOptions := []FormOption{
 		{Id: "", Name: "Alphabetisch"},
+		{Id: "categories", Name: "Kategorien"},
 	}
 
-	categories, err := env.queries.GetCategories(tx)
+	stores, err := env.queries.GetStores(tx, user.HouseholdId)
 	if err != nil {
 		respondWithErrorPage(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	for _, category := range categories {
+	for _, store := range stores {
+		id := fmt.Sprintf("store-%d", store.Id)
 		sortOptions = append(sortOptions, FormOption{
-			Id:   strconv.Itoa(category.Id),
-			Name: category.Name,
+			Id:   id,
+			Name: store.Name,
 		})
-		sortSet[strconv.Itoa(category.Id)] = true
+		sortSet[id] = true
 	}
 
+	// Bookmarks and cached pages may still sort by a category id or a removed store, those fall back to the default order.
 	if !sortSet[sortBy] {
-		respondWithErrorPage(w, http.StatusBadRequest, errors.New(fmt.Sprintf("Unbekannter Wert `%s` für `sort-by`.", sortBy)))
-		return
+		sortBy = ""
 	}
 
 	var addedItems []types.AddedItem
 	if sortBy == "" {
-		addedItems, err = env.queries.GetRemainingItemsByAlphabet(tx)
+		addedItems, err = env.queries.GetRemainingItemsByAlphabet(tx, list.Id)
+	} else if sortBy == "categories" {
+		addedItems, err = env.queries.GetRemainingItemsByCategory(tx, list.Id)
 	} else {
-		categoryId, err := strconv.Atoi(sortBy)
-
+		var storeId int
+		storeId, err = strconv.Atoi(strings.TrimPrefix(sortBy, "store-"))
 		if err != nil {
 			respondWithErrorPage(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		addedItems, err = env.queries.GetRemainingItemsByCategory(tx, categoryId)
+		addedItems, err = env.queries.GetRemainingItemsByStore(tx, list.Id, storeId)
 	}
 
 	if err != nil {
@@ -70,7 +79,7 @@ func (env *Environment) ShopRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gatheredItems, err := env.queries.GetGatheredItems(tx)
+	gatheredItems, err := env.queries.GetGatheredItems(tx, list.Id)
 	if err != nil {
 		respondWithErrorPage(w, http.StatusInternalServerError, err)
 		return
@@ -87,9 +96,10 @@ func (env *Environment) ShopRoute(w http.ResponseWriter, r *http.Request) {
 		"layouts/internal.html",
 	}
 
-	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
 	data := struct {
 		CurrentUser    types.User
+		CurrentList    types.List
+		Lists          []types.List
 		Products       []types.Product
 		AddedItems     []types.AddedItem
 		GatheredItems  []types.AddedItem
@@ -98,6 +108,8 @@ func (env *Environment) ShopRoute(w http.ResponseWriter, r *http.Request) {
 		IdempotencyKey string
 	}{
 		CurrentUser:    user,
+		CurrentList:    list,
+		Lists:          lists,
 		AddedItems:     addedItems,
 		GatheredItems:  gatheredItems,
 		SortOptions:    sortOptions,
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"stravid.com/besserliste/types"
+	"stravid.com/besserliste/web"
+)
+
+var monthNames = []string{"Jänner", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}
+
+func (env *Environment) SpendingRoute(w http.ResponseWriter, r *http.Request) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+
+	user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+	list, _ := r.Context().Value(contextKeyCurrentList).(types.List)
+	lists, _ := r.Context().Value(contextKeyLists).([]types.List)
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	month := currentMonth
+
+	if r.URL.Query().Get("month") != "" {
+		month, err = time.ParseInLocation("2006-01", r.URL.Query().Get("month"), time.Local)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusBadRequest, errors.New("Monat muss im Format `JJJJ-MM` angegeben werden."))
+			return
+		}
+	}
+
+	purchases, err := env.queries.GetPurchases(tx, user.HouseholdId, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	report := types.SummarizeSpending(purchases)
+
+	// This is here because the queries require a transaction even though this handler does not make any database changes.
+	err = tx.Commit()
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	nextMonth := ""
+	if month.Before(currentMonth) {
+		nextMonth = month.AddDate(0, 1, 0).Format("2006-01")
+	}
+
+	data := struct {
+		CurrentUser    types.User
+		CurrentList    types.List
+		Lists          []types.List
+		FormattedMonth string
+		PreviousMonth  string
+		NextMonth      string
+		Report         *types.SpendingReport
+	}{
+		CurrentUser:    user,
+		CurrentList:    list,
+		Lists:          lists,
+		FormattedMonth: monthNames[month.Month()-1] + " " + month.Format("2006"),
+		PreviousMonth:  month.AddDate(0, -1, 0).Format("2006-01"),
+		NextMonth:      nextMonth,
+		Report:         &report,
+	}
+
+	files := []string{
+		"screens/spending.html",
+		"layouts/internal.html",
+	}
+
+	ts, err := template.ParseFS(web.Templates, files...)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = ts.Execute(w, data)
+	if err != nil {
+		respondWithErrorPage(w, http.StatusInternalServerError, err)
+		return
+	}
+}
@@ -37,26 +37,13 @@ func (env *Environment) UndoRoute(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		item, err := env.queries.GetItem(tx, itemId)
+		item, err := env.transitionItem(tx, user.HouseholdId, user.Id, itemId, oldState, newState)
 		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		if item.State != oldState {
-			respondWithErrorPage(w, http.StatusInternalServerError, errors.New(fmt.Sprintf("Expected state %s but got %s", oldState, item.State)))
-			return
-		}
-
-		err = env.queries.SetItemState(tx, item.Id, newState)
-		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		err = env.queries.InsertItemChange(tx, int64(item.Id), user.Id, item.Dimension.Id, int64(item.Quantity), newState)
-		if err != nil {
-			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			if errors.Is(err, errWrongItemState) {
+				respondWithErrorPage(w, http.StatusBadRequest, err)
+			} else {
+				respondWithErrorPage(w, http.StatusInternalServerError, err)
+			}
 			return
 		}
 
@@ -77,6 +64,8 @@ func (env *Environment) UndoRoute(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		env.events.publish(itemEvent{ListId: item.ListId, ItemId: item.Id, State: item.State, UserId: user.Id})
+
 		if oldState == "removed" {
 			http.Redirect(w, r, "/plan", http.StatusSeeOther)
 		} else {
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// New households start with the categories and list the first household got from migrations.
+var defaultCategories = []string{
+	"Obst & Gemüse",
+	"Kühlregal",
+	"Theke",
+	"Verpackt",
+	"Getränke",
+	"Tiefkühlregal",
+	"Haushalt",
+	"Sonstiges",
+}
+
+const defaultListName = "Supermarkt"
+
+// runCommand executes the administrative command given on the command line instead of starting the web server.
+func (env *Environment) runCommand(args []string) error {
+	switch args[0] {
+	case "add-household":
+		if len(args) != 4 {
+			return errors.New("Usage: besserliste add-household <household name> <user name> <user email>")
+		}
+
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+
+		householdId, userId, err := env.addHousehold(args[1], args[2], args[3], password)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Added household %d with user %d\n", householdId, userId)
+		return nil
+	case "add-user":
+		if len(args) != 4 {
+			return errors.New("Usage: besserliste add-user <household id> <user name> <user email>")
+		}
+
+		householdId, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("Invalid household id `%s`", args[1])
+		}
+
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+
+		userId, err := env.addUser(householdId, args[2], args[3], password)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Added user %d to household %d\n", userId, householdId)
+		return nil
+	case "set-password":
+		if len(args) != 2 {
+			return errors.New("Usage: besserliste set-password <user email>")
+		}
+
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+
+		userId, err := env.changePassword(args[1], password)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Set password of user %d\n", userId)
+		return nil
+	case "backup":
+		if len(args) != 2 {
+			return errors.New("Usage: besserliste backup <path>")
+		}
+
+		err := backupDatabase(env.db, args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Backed up database to %s\n", args[1])
+		return nil
+	case "restore":
+		if len(args) != 2 {
+			return errors.New("Usage: besserliste restore <path>")
+		}
+
+		err := env.restoreDatabase(args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored database from %s\n", args[1])
+		return nil
+	case "export":
+		if len(args) != 2 {
+			return errors.New("Usage: besserliste export <path>")
+		}
+
+		err := env.exportData(args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Exported all data to %s\n", args[1])
+		return nil
+	case "import":
+		if len(args) != 2 {
+			return errors.New("Usage: besserliste import <path>, replaces all households and their history")
+		}
+
+		err := env.importData(args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported all data from %s\n", args[1])
+		return nil
+	default:
+		return fmt.Errorf("Unknown command `%s`", args[0])
+	}
+}
+
+// addHousehold creates a household with its first user, the default categories and a default list.
+func (env *Environment) addHousehold(name string, userName string, email string, password string) (int64, int64, error) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := env.queries.InsertHousehold(tx, name)
+	if err != nil {
+		if err.Error() == "UNIQUE constraint failed: index 'idx_households_name'" {
+			return 0, 0, fmt.Errorf("Household `%s` already exists", name)
+		}
+		return 0, 0, err
+	}
+
+	householdId, err := result.LastInsertId()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	userId, err := env.insertUser(tx, int(householdId), userName, email, password)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, category := range defaultCategories {
+		_, err = env.queries.InsertCategory(tx, int(householdId), category)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	_, err = env.queries.InsertList(tx, int(householdId), defaultListName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return householdId, userId, tx.Commit()
+}
+
+func (env *Environment) addUser(householdId int, name string, email string, password string) (int64, error) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = env.queries.GetHousehold(tx, householdId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("Household %d does not exist", householdId)
+		}
+		return 0, err
+	}
+
+	userId, err := env.insertUser(tx, householdId, name, email, password)
+	if err != nil {
+		return 0, err
+	}
+
+	return userId, tx.Commit()
+}
+
+func (env *Environment) changePassword(email string, password string) (int, error) {
+	tx, err := env.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	user, err := env.queries.GetUserByEmail(tx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("User `%s` does not exist", email)
+		}
+		return 0, err
+	}
+
+	err = env.setPassword(tx, user.Id, password)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.Id, tx.Commit()
+}
+
+func (env *Environment) insertUser(tx *sql.Tx, householdId int, name string, email string, password string) (int64, error) {
+	result, err := env.queries.InsertUser(tx, householdId, name, email)
+	if err != nil {
+		if err.Error() == "UNIQUE constraint failed: users.email" {
+			return 0, fmt.Errorf("Email `%s` is already in use", email)
+		} else if err.Error() == "UNIQUE constraint failed: index 'idx_users_name'" {
+			return 0, fmt.Errorf("User `%s` already exists in this household", name)
+		}
+		return 0, err
+	}
+
+	userId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return userId, env.setPassword(tx, int(userId), password)
+}
+
+// readPassword reads the password of a user from the first line of standard input.
+func readPassword() (string, error) {
+	fmt.Print("Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	formErrors := make(map[string]string)
+	validateNewPassword(password, password, formErrors)
+	if message, ok := formErrors["new_password"]; ok {
+		return "", errors.New(message)
+	}
+
+	return password, nil
+}
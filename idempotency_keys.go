@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
@@ -13,7 +14,7 @@ func IdempotencyKey() string {
 	return base64.RawURLEncoding.EncodeToString(key)[0:32]
 }
 
-func (env *Environment) idempotencyKeysCleaner() {
+func (env *Environment) idempotencyKeysCleaner(ctx context.Context) {
 	for {
 		tx, err := env.db.Begin()
 		if err != nil {
@@ -32,6 +33,10 @@ func (env *Environment) idempotencyKeysCleaner() {
 			}
 		}
 
-		time.Sleep(60 * 60 * time.Second)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(60 * 60 * time.Second):
+		}
 	}
 }
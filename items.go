@@ -0,0 +1,305 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"stravid.com/besserliste/types"
+)
+
+var errWrongItemState = errors.New("Eintrag befindet sich im falschen Zustand.")
+
+// Items hold at most this many base units, for example 10 kg or 10 l.
+const maxItemQuantity = 10000
+
+const maxItemNoteLength = 100
+
+// transitionItem moves an item along the state machine described in docs/item-state-machine.txt and records the change.
+func (env *Environment) transitionItem(tx *sql.Tx, householdId int, userId int, itemId int, oldState string, newState string) (*types.SelectedItem, error) {
+	item, err := env.queries.GetItem(tx, householdId, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.State != oldState {
+		return nil, errWrongItemState
+	}
+
+	err = env.queries.SetItemState(tx, item.Id, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.recordItemChange(tx, newItemAction(userId, "do"), int64(item.Id), item.Dimension.Id, int64(item.Quantity), item.Note, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.stockItem(tx, item, oldState, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	item.State = newState
+	return item, nil
+}
+
+// addItem sums the amount into the added item of the same product and dimension or puts a new item on the list.
+// The note is appended to the note of the existing item.
+// Invalid input is reported through formErrors and leaves the list untouched.
+func (env *Environment) addItem(tx *sql.Tx, userId int, listId int, product *types.SelectedProduct, unitId string, amount string, note string, formErrors map[string]string) (int64, error) {
+	unit, dimension, ok := findUnit(product, unitId)
+	if !ok {
+		formErrors["unit_id"] = "Maßeinheit wählen"
+	}
+
+	parsedQuantity := parseQuantity(amount, formErrors)
+	validateNote(note, formErrors)
+	if len(formErrors) != 0 {
+		return 0, nil
+	}
+
+	startQuantiy := int64(0)
+	item, err := env.queries.GetAddedItemByProductDimension(tx, listId, product.Id, dimension.Id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+	} else {
+		startQuantiy = int64(item.Quantity)
+
+		if utf8.RuneCountInString(mergeNotes(item.Note, note)) > maxItemNoteLength {
+			formErrors["note"] = fmt.Sprintf("Kürzere Notiz angeben (zusammen mit der bestehenden Notiz „%s“ maximal %d Zeichen)", item.Note, maxItemNoteLength)
+		}
+	}
+
+	baseQuantity := toBaseQuantity(parsedQuantity, unit, startQuantiy, formErrors)
+	if len(formErrors) != 0 {
+		return 0, nil
+	}
+
+	return env.addBaseQuantity(tx, newItemAction(userId, "do"), listId, product.Id, dimension.Id, baseQuantity, note)
+}
+
+// addBaseQuantity is addItem for quantities which are already validated and converted to the base unit of the dimension.
+// The sum is capped at the largest quantity an item can have, the merged note at the longest note.
+// The change becomes part of the given action, so callers adding several items can undo them together.
+func (env *Environment) addBaseQuantity(tx *sql.Tx, action *itemAction, listId int, productId int, dimensionId int, baseQuantity int64, note string) (int64, error) {
+	startQuantiy := int64(0)
+	itemId := int64(0)
+	existingNote := ""
+	item, err := env.queries.GetAddedItemByProductDimension(tx, listId, productId, dimensionId)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+	} else {
+		startQuantiy = int64(item.Quantity)
+		itemId = int64(item.Id)
+		existingNote = item.Note
+	}
+
+	mergedNote := truncateNote(mergeNotes(existingNote, note))
+
+	quantity := baseQuantity + startQuantiy
+	if quantity > maxItemQuantity {
+		quantity = maxItemQuantity
+	}
+
+	if itemId == 0 {
+		result, err := env.queries.InsertItem(tx, listId, productId, dimensionId, quantity, mergedNote)
+		if err != nil {
+			return 0, err
+		}
+
+		itemId, err = result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		err = env.queries.SetItemQuantity(tx, itemId, quantity)
+		if err != nil {
+			return 0, err
+		}
+
+		if mergedNote != existingNote {
+			err = env.queries.SetItemNote(tx, itemId, mergedNote)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	err = env.recordItemChange(tx, action, itemId, dimensionId, quantity, mergedNote, "added")
+	if err != nil {
+		return 0, err
+	}
+
+	return itemId, nil
+}
+
+// setItemQuantity replaces quantity and note of an added item.
+// Switching to a dimension which already has an added item merges both items into that one.
+func (env *Environment) setItemQuantity(tx *sql.Tx, userId int, item *types.SelectedItem, product *types.SelectedProduct, unitId string, amount string, note string, formErrors map[string]string) error {
+	if item.State != "added" {
+		return errWrongItemState
+	}
+
+	unit, dimension, ok := findUnit(product, unitId)
+	if !ok {
+		formErrors["unit_id"] = "Maßeinheit wählen"
+	}
+
+	parsedQuantity := parseQuantity(amount, formErrors)
+	validateNote(note, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	startQuantiy := int64(0)
+	itemIdForSelectedDimension := int64(0)
+	itemForSelectedDimension, err := env.queries.GetAddedItemByProductDimension(tx, item.ListId, product.Id, dimension.Id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	} else {
+		startQuantiy = int64(itemForSelectedDimension.Quantity)
+		itemIdForSelectedDimension = int64(itemForSelectedDimension.Id)
+	}
+
+	baseQuantity := toBaseQuantity(parsedQuantity, unit, startQuantiy, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	action := newItemAction(userId, "do")
+	initialItemNeedsToBeRemoved := item.Dimension.Id != dimension.Id && itemIdForSelectedDimension != 0
+
+	if initialItemNeedsToBeRemoved {
+		mergedNote := mergeNotes(itemForSelectedDimension.Note, note)
+		if utf8.RuneCountInString(mergedNote) > maxItemNoteLength {
+			formErrors["note"] = fmt.Sprintf("Kürzere Notiz angeben (zusammen mit der bestehenden Notiz „%s“ maximal %d Zeichen)", itemForSelectedDimension.Note, maxItemNoteLength)
+			return nil
+		}
+
+		// Remove selected item
+		err = env.queries.SetItemState(tx, item.Id, "removed")
+		if err != nil {
+			return err
+		}
+
+		err = env.recordItemChange(tx, action, int64(item.Id), item.Dimension.Id, int64(item.Quantity), item.Note, "removed")
+		if err != nil {
+			return err
+		}
+
+		// Update existing item
+		err = env.queries.SetItemQuantity(tx, itemIdForSelectedDimension, baseQuantity+startQuantiy)
+		if err != nil {
+			return err
+		}
+
+		err = env.queries.SetItemNote(tx, itemIdForSelectedDimension, mergedNote)
+		if err != nil {
+			return err
+		}
+
+		return env.recordItemChange(tx, action, itemIdForSelectedDimension, itemForSelectedDimension.Dimension.Id, baseQuantity+startQuantiy, mergedNote, "added")
+	} else {
+		// Update existing item
+		err = env.queries.SetItemQuantityForDifferentDimension(tx, item.Id, baseQuantity, dimension.Id)
+		if err != nil {
+			return err
+		}
+
+		err = env.queries.SetItemNote(tx, int64(item.Id), note)
+		if err != nil {
+			return err
+		}
+
+		return env.recordItemChange(tx, action, int64(item.Id), dimension.Id, baseQuantity, note, "added")
+	}
+}
+
+func validateNote(note string, formErrors map[string]string) {
+	if utf8.RuneCountInString(note) > maxItemNoteLength {
+		formErrors["note"] = fmt.Sprintf("Kürzere Notiz angeben (maximal %d Zeichen)", maxItemNoteLength)
+	}
+}
+
+// mergeNotes keeps both notes when two items are summed up, unless the existing note already says the same.
+func mergeNotes(existing string, added string) string {
+	if added == "" {
+		return existing
+	}
+
+	if existing == "" {
+		return added
+	}
+
+	for _, part := range strings.Split(existing, "; ") {
+		if part == added {
+			return existing
+		}
+	}
+
+	return existing + "; " + added
+}
+
+func truncateNote(note string) string {
+	runes := []rune(note)
+	if len(runes) > maxItemNoteLength {
+		return string(runes[:maxItemNoteLength])
+	}
+
+	return note
+}
+
+func findUnit(product *types.SelectedProduct, unitId string) (types.Unit, types.Dimension, bool) {
+	for _, dimension := range product.Dimensions {
+		for _, unit := range dimension.Units {
+			if strconv.Itoa(unit.Id) == unitId {
+				return unit, dimension, true
+			}
+		}
+	}
+
+	return types.Unit{}, types.Dimension{}, false
+}
+
+func parseQuantity(amount string, formErrors map[string]string) float64 {
+	parsedQuantity, err := strconv.ParseFloat(strings.Replace(amount, ",", ".", -1), 64)
+
+	if amount == "" {
+		formErrors["quantity"] = "Menge angeben"
+	} else if err != nil {
+		formErrors["quantity"] = "Zahl angeben"
+	}
+
+	return parsedQuantity
+}
+
+func toBaseQuantity(parsedQuantity float64, unit types.Unit, startQuantiy int64, formErrors map[string]string) int64 {
+	remainingQuanity := int64(maxItemQuantity - startQuantiy)
+	parsedBaseQuantity := parsedQuantity * unit.ConversionToBase
+	baseQuantity := int64(parsedBaseQuantity)
+
+	if parsedBaseQuantity != float64(baseQuantity) {
+		formErrors["quantity"] = "Ganze Zahl angeben"
+	}
+
+	if baseQuantity < 1 {
+		formErrors["amount"] = "Größere Menge angeben (kleinste Menge ist 1)"
+	}
+
+	if baseQuantity > remainingQuanity {
+		formErrors["amount"] = fmt.Sprintf("Kleinere Menge angeben (größte Menge ist %d)", int64(unit.ConversionFromBase*float64(remainingQuanity)))
+	}
+
+	return baseQuantity
+}
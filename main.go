@@ -3,56 +3,115 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"github.com/golangcollege/sessions"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime/debug"
 	"stravid.com/besserliste/migrations"
 	"stravid.com/besserliste/queries"
 	"stravid.com/besserliste/types"
 	"stravid.com/besserliste/web"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// Requests have to be answered within handlerTimeout, a shutdown waits at most shutdownTimeout for them.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	handlerTimeout    = 30 * time.Second
+	idleTimeout       = 120 * time.Second
+	shutdownTimeout   = 40 * time.Second
+)
+
+// databaseDriver loads the de_AT collation on every new connection of the pool, otherwise sorting and comparing names fails on all but the first one.
+const databaseDriver = "sqlite3_de_AT"
+
+func init() {
+	sql.Register(databaseDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec("SELECT icu_load_collation('de_AT', 'de_AT');", nil)
+			return err
+		},
+	})
+}
+
 func main() {
-	configurationFile, _ := os.Open("config.json")
-	defer configurationFile.Close()
-	decoder := json.NewDecoder(configurationFile)
-	configuration := Configuration{}
-	err := decoder.Decode(&configuration)
+	// Flags come before administrative commands, for example `besserliste -config /etc/besserliste.json add-user …`.
+	configurationPath := flag.String("config", defaultConfigurationPath, "path of the JSON configuration file")
+	flag.Parse()
+
+	explicitConfigurationPath := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicitConfigurationPath = true
+		}
+	})
+
+	configuration, err := loadConfiguration(*configurationPath, explicitConfigurationPath)
 	if err != nil {
-		log.Fatalln("Error opening config.json: ", err.Error())
+		log.Fatalln(err.Error())
 	}
 
-	db, err := sql.Open("sqlite3", fmt.Sprintf("%s?_foreign_keys=on", configuration.Database))
+	db, err := sql.Open(databaseDriver, fmt.Sprintf("%s?_foreign_keys=on", configuration.Database))
 	if err != nil {
 		log.Fatalln("Error opening database: ", err.Error())
 	}
 	defer db.Close()
 
-	// Load correct collation so our sorting works as expected.
-	_, err = db.Exec("SELECT icu_load_collation('de_AT', 'de_AT');")
+	// Opens the first connection, which fails if the collation cannot be loaded.
+	err = db.Ping()
 	if err != nil {
-		log.Fatalln("Error loading collation: ", err.Error())
+		log.Fatalln("Error opening database: ", err.Error())
+	}
+
+	// Databases are backed up before migrating, next to the scheduled backups or otherwise next to the database itself.
+	backupDirectory := configuration.BackupDirectory
+	if backupDirectory == "" {
+		backupDirectory = filepath.Dir(configuration.Database)
+	}
+
+	// Migration commands run before the migrations at boot, otherwise nothing would ever be pending.
+	if flag.Arg(0) == "migrate" {
+		err = runMigrateCommand(db, flag.Args()[1:], preMigrationBackup(db, backupDirectory))
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		return
 	}
 
 	// Run migrations at boot to get current database schema.
-	migrations.Run(db)
+	err = migrations.Run(db, preMigrationBackup(db, backupDirectory))
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
 
 	session := sessions.New([]byte(configuration.Secret))
 	session.Lifetime = 30 * 24 * time.Hour
 
 	env := &Environment{
-		queries:  queries.Build(db),
-		session:  session,
-		db:       db,
-		password: configuration.Password,
+		queries: queries.Build(db),
+		session: session,
+		db:      db,
+		events:  newEventBroker(),
+	}
+
+	// Administrative commands like `besserliste add-household` run instead of the web server.
+	if flag.NArg() > 0 {
+		err = env.runCommand(flag.Args())
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		return
 	}
 
 	fileServer := http.FileServer(http.FS(web.Static))
@@ -64,11 +123,43 @@ func main() {
 
 	// Internal HTTP handlers require a signed-in user.
 	internalHandler := func(handler func(http.ResponseWriter, *http.Request)) http.Handler {
-		return env.session.Enable(env.authenticate(env.requireAuthentication(http.HandlerFunc(handler))))
+		return env.session.Enable(env.authenticate(env.requireAuthentication(env.selectList(http.HandlerFunc(handler)))))
+	}
+
+	// API HTTP handlers require a signed-in user and answer with JSON.
+	apiHandler := func(handler func(http.ResponseWriter, *http.Request)) http.Handler {
+		return env.session.Enable(env.authenticate(env.requireApiAuthentication(env.selectList(http.HandlerFunc(handler)))))
+	}
+
+	// SIGTERM is what systemd sends on stop and restart, Ctrl+C is handy during development.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// Background Go routines stop once ctx is cancelled, they must be done before the database is closed.
+	background := sync.WaitGroup{}
+	runInBackground := func(job func(context.Context)) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			job(ctx)
+		}()
 	}
 
 	// Start background Go routine that periodically removes old idempotency keys.
-	go env.idempotencyKeysCleaner()
+	runInBackground(env.idempotencyKeysCleaner)
+
+	// Start background Go routine that periodically removes old failed login attempts.
+	runInBackground(env.failedLoginsCleaner)
+
+	// Start background Go routine that periodically puts due recurring items on their list.
+	runInBackground(env.recurringItemsScheduler)
+
+	// Start background Go routine that periodically backs up the database, if a directory for the backups is configured.
+	if configuration.BackupDirectory != "" {
+		runInBackground(func(ctx context.Context) {
+			env.backupScheduler(ctx, configuration.BackupDirectory, time.Duration(configuration.BackupIntervalHours)*time.Hour, configuration.BackupRetention)
+		})
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/static/", fileServer)
@@ -77,6 +168,7 @@ func main() {
 	mux.Handle("/logout", internalHandler(env.LogoutRoute))
 	mux.Handle("/plan", internalHandler(env.PlanRoute))
 	mux.Handle("/add-product", internalHandler(env.AddProductRoute))
+	mux.Handle("/search-products", internalHandler(env.SearchProductsRoute))
 	mux.Handle("/add-item", internalHandler(env.AddItemRoute))
 	mux.Handle("/shop", internalHandler(env.ShopRoute))
 	mux.Handle("/check-item", internalHandler(env.CheckItemRoute))
@@ -84,11 +176,97 @@ func main() {
 	mux.Handle("/home", internalHandler(env.HomeRoute))
 	mux.Handle("/undo", internalHandler(env.UndoRoute))
 	mux.Handle("/set-quantity", internalHandler(env.SetQuantityRoute))
+	mux.Handle("/lists", internalHandler(env.ListsRoute))
+	mux.Handle("/select-list", internalHandler(env.SelectListRoute))
+	mux.Handle("/change-password", internalHandler(env.ChangePasswordRoute))
+	mux.Handle("/history", internalHandler(env.HistoryRoute))
+	mux.Handle("/spending", internalHandler(env.SpendingRoute))
+	mux.Handle("/audit", internalHandler(env.AuditRoute))
+	mux.Handle("/actions", internalHandler(env.ActionsRoute))
+	mux.Handle("/recurring-items", internalHandler(env.RecurringItemsRoute))
+	mux.Handle("/add-recurring-item", internalHandler(env.AddRecurringItemRoute))
+	mux.Handle("/remove-recurring-item", internalHandler(env.RemoveRecurringItemRoute))
+	mux.Handle("/products", internalHandler(env.ProductsRoute))
+	mux.Handle("/edit-product", internalHandler(env.EditProductRoute))
+	mux.Handle("/merge-product", internalHandler(env.MergeProductRoute))
+	mux.Handle("/remove-product", internalHandler(env.RemoveProductRoute))
+	mux.Handle("/product-prices", internalHandler(env.ProductPricesRoute))
+	mux.Handle("/add-product-alias", internalHandler(env.AddProductAliasRoute))
+	mux.Handle("/remove-product-alias", internalHandler(env.RemoveProductAliasRoute))
+	mux.Handle("/pantry", internalHandler(env.PantryRoute))
+	mux.Handle("/consume-stock", internalHandler(env.ConsumeStockRoute))
+	mux.Handle("/set-stock-minimum", internalHandler(env.SetStockMinimumRoute))
+	mux.Handle("/recipes", internalHandler(env.RecipesRoute))
+	mux.Handle("/add-recipe", internalHandler(env.AddRecipeRoute))
+	mux.Handle("/edit-recipe", internalHandler(env.EditRecipeRoute))
+	mux.Handle("/remove-recipe", internalHandler(env.RemoveRecipeRoute))
+	mux.Handle("/add-recipe-ingredient", internalHandler(env.AddRecipeIngredientRoute))
+	mux.Handle("/remove-recipe-ingredient", internalHandler(env.RemoveRecipeIngredientRoute))
+	mux.Handle("/add-recipe-to-list", internalHandler(env.AddRecipeToListRoute))
+	mux.Handle("/categories", internalHandler(env.CategoriesRoute))
+	mux.Handle("/add-category", internalHandler(env.AddCategoryRoute))
+	mux.Handle("/edit-category", internalHandler(env.EditCategoryRoute))
+	mux.Handle("/remove-category", internalHandler(env.RemoveCategoryRoute))
+	mux.Handle("/reorder-categories", internalHandler(env.ReorderCategoriesRoute))
+	mux.Handle("/stores", internalHandler(env.StoresRoute))
+	mux.Handle("/add-store", internalHandler(env.AddStoreRoute))
+	mux.Handle("/edit-store", internalHandler(env.EditStoreRoute))
+	mux.Handle("/remove-store", internalHandler(env.RemoveStoreRoute))
+	mux.Handle("/reorder-store", internalHandler(env.ReorderStoreRoute))
+	mux.Handle("/service-worker.js", http.HandlerFunc(env.ServiceWorkerRoute))
+	mux.Handle("/api/v1/login", externalHandler(env.ApiLoginRoute))
+	mux.Handle("/api/v1/lists", apiHandler(env.ApiListsRoute))
+	mux.Handle("/api/v1/items", apiHandler(env.ApiItemsRoute))
+	mux.Handle("/api/v1/items/check", apiHandler(env.ApiCheckItemRoute))
+	mux.Handle("/api/v1/items/remove", apiHandler(env.ApiRemoveItemRoute))
+	mux.Handle("/api/v1/items/undo", apiHandler(env.ApiUndoRoute))
+	mux.Handle("/api/v1/items/quantity", apiHandler(env.ApiSetQuantityRoute))
+	mux.Handle("/api/v1/products", apiHandler(env.ApiProductsRoute))
+	mux.Handle("/api/v1/product", apiHandler(env.ApiProductRoute))
+	mux.Handle("/api/v1/categories", apiHandler(env.ApiCategoriesRoute))
+	mux.Handle("/api/v1/dimensions", apiHandler(env.ApiDimensionsRoute))
+
+	// Handlers get their deadline from http.TimeoutHandler instead of the WriteTimeout of the server, which would also cut off the long-lived `/events` streams.
+	root := http.NewServeMux()
+	root.Handle("/events", http.HandlerFunc(env.EventsRoute))
+	root.Handle("/", http.TimeoutHandler(mux, handlerTimeout, "Die Anfrage hat zu lange gedauert."))
+
+	server := &http.Server{
+		Addr:              configuration.Listen,
+		Handler:           root,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	server.RegisterOnShutdown(env.events.close)
 
-	err = http.ListenAndServe(configuration.Listen, mux)
-	if err != nil {
+	// Shutdown stops accepting connections and waits for running requests, so form posts are not cut off by a restart.
+	shutdownComplete := make(chan struct{})
+	go func() {
+		defer close(shutdownComplete)
+		<-ctx.Done()
+		log.Println("Shutting down Besserliste web application")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Println("Error shutting down Besserliste web application: ", err.Error())
+		}
+	}()
+
+	if configuration.TlsCertificate != "" {
+		err = server.ListenAndServeTLS(configuration.TlsCertificate, configuration.TlsKey)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalln("Error starting Besserliste web application: ", err.Error())
 	}
+
+	<-shutdownComplete
+	background.Wait()
 }
 
 func respondWithErrorPage(w http.ResponseWriter, statusCode int, err error) {
@@ -128,10 +306,12 @@ type FormOption struct {
 type contextKey string
 
 const contextKeyCurrentUser = contextKey("currentUser")
+const contextKeyCurrentList = contextKey("currentList")
+const contextKeyLists = contextKey("lists")
 
 func (env *Environment) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		exists := env.session.Exists(r, "user_id")
+		exists := env.session.Exists(r, "user_id") && env.session.Exists(r, "household_id")
 		if !exists {
 			next.ServeHTTP(w, r)
 			return
@@ -144,15 +324,17 @@ func (env *Environment) authenticate(next http.Handler) http.Handler {
 		}
 		defer tx.Rollback()
 
-		user, err := env.queries.GetUserById(tx, env.session.GetInt(r, "user_id"))
+		user, err := env.queries.GetUserById(tx, env.session.GetInt(r, "household_id"), env.session.GetInt(r, "user_id"))
 
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				env.session.Remove(r, "user_id")
+				env.session.Remove(r, "household_id")
 				next.ServeHTTP(w, r)
 				return
 			} else {
 				respondWithErrorPage(w, http.StatusInternalServerError, err)
+				return
 			}
 		}
 
@@ -178,6 +360,49 @@ func (env *Environment) requireAuthentication(next http.Handler) http.Handler {
 	})
 }
 
+// selectList puts all lists and the one chosen in the session into the request context, falling back to the first list.
+func (env *Environment) selectList(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tx, err := env.db.Begin()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+		defer tx.Rollback()
+
+		user, _ := r.Context().Value(contextKeyCurrentUser).(types.User)
+
+		lists, err := env.queries.GetLists(tx, user.HouseholdId)
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithErrorPage(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(lists) == 0 {
+			respondWithErrorPage(w, http.StatusInternalServerError, errors.New("Es gibt keine Einkaufsliste."))
+			return
+		}
+
+		currentList := lists[0]
+		listId := env.session.GetInt(r, "list_id")
+		for _, list := range lists {
+			if list.Id == listId {
+				currentList = list
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeyCurrentList, currentList)
+		ctx = context.WithValue(ctx, contextKeyLists, lists)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (env *Environment) isAuthenticated(r *http.Request) bool {
 	_, ok := r.Context().Value(contextKeyCurrentUser).(types.User)
 
@@ -189,15 +414,8 @@ func (env *Environment) isAuthenticated(r *http.Request) bool {
 }
 
 type Environment struct {
-	queries  queries.Queries
-	session  *sessions.Session
-	db       *sql.DB
-	password string
-}
-
-type Configuration struct {
-	Database string
-	Secret   string
-	Listen   string
-	Password string
+	queries queries.Queries
+	session *sessions.Session
+	db      *sql.DB
+	events  *eventBroker
 }
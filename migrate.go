@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"stravid.com/besserliste/migrations"
+)
+
+const migrateUsage = "Usage: besserliste migrate status | besserliste migrate up [--dry-run]"
+
+// runMigrateCommand shows and applies migrations without starting the web application.
+func runMigrateCommand(db *sql.DB, args []string, beforeMigrating func(version int) (string, error)) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrations.Statuses(db)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "Migration\tState\tApplied at\tChecksum")
+		for _, s := range statuses {
+			state := "pending"
+			if s.Changed() {
+				state = "changed since applied"
+			} else if s.Applied {
+				state = "applied"
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", s.Name, state, s.AppliedAt, s.Checksum[:12])
+		}
+		return writer.Flush()
+	case args[0] == "up" && len(args) == 2 && args[1] == "--dry-run":
+		pending, err := migrations.Pending(db)
+		if err != nil {
+			return err
+		}
+
+		if len(pending) == 0 {
+			fmt.Println("Database is up to date")
+		}
+
+		for _, migration := range pending {
+			fmt.Printf("Would run migration %s\n", migration.Name)
+		}
+		return nil
+	case args[0] == "up" && len(args) == 1:
+		err := migrations.Run(db, beforeMigrating)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Database is up to date")
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,17 @@
+CREATE TABLE recurring_items (
+  id INTEGER PRIMARY KEY,
+  list_id INTEGER NOT NULL,
+  product_id INTEGER NOT NULL,
+  dimension_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  quantity INTEGER NOT NULL CHECK(quantity > 0 AND quantity <= 10000),
+  interval_days INTEGER NOT NULL CHECK(interval_days > 0 AND interval_days <= 365),
+  next_due_at DATETIME NOT NULL,
+  FOREIGN KEY(list_id) REFERENCES lists(id),
+  FOREIGN KEY(product_id) REFERENCES products(id),
+  FOREIGN KEY(dimension_id) REFERENCES dimensions(id),
+  FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_recurring_items_next_due_at ON recurring_items(next_due_at);
+CREATE INDEX idx_recurring_items_list_id ON recurring_items(list_id);
@@ -0,0 +1,20 @@
+CREATE TABLE stores (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  name TEXT NOT NULL CHECK(length(name) <= 20) COLLATE de_AT,
+  FOREIGN KEY(household_id) REFERENCES households(id)
+);
+
+CREATE UNIQUE INDEX idx_stores_name ON stores(household_id, lower(name, 'de_AT'));
+
+CREATE TABLE stores_categories (
+  store_id INTEGER NOT NULL,
+  category_id INTEGER NOT NULL,
+  ordering INTEGER NOT NULL,
+  FOREIGN KEY(store_id) REFERENCES stores(id),
+  FOREIGN KEY(category_id) REFERENCES categories(id)
+);
+
+CREATE UNIQUE INDEX idx_stores_categories ON stores_categories(store_id, category_id);
+CREATE UNIQUE INDEX idx_stores_categories_ordering ON stores_categories(store_id, ordering);
+CREATE INDEX idx_stores_categories_category_id ON stores_categories(category_id);
@@ -0,0 +1,2 @@
+ALTER TABLE items ADD COLUMN note TEXT NOT NULL DEFAULT '' CHECK(length(note) <= 100);
+ALTER TABLE item_changes ADD COLUMN note TEXT NOT NULL DEFAULT '' CHECK(length(note) <= 100);
@@ -0,0 +1 @@
+ALTER TABLE item_changes ADD COLUMN price INTEGER CHECK(price IS NULL OR (price > 0 AND price <= 1000000));
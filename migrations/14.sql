@@ -0,0 +1,8 @@
+CREATE TABLE product_trigrams (
+  product_id INTEGER NOT NULL,
+  trigram TEXT NOT NULL,
+  FOREIGN KEY(product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX idx_product_trigrams ON product_trigrams(product_id, trigram);
+CREATE INDEX idx_product_trigrams_trigram ON product_trigrams(trigram);
@@ -0,0 +1,11 @@
+CREATE TABLE product_aliases (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  product_id INTEGER NOT NULL,
+  name TEXT NOT NULL CHECK(length(name) <= 40) COLLATE de_AT,
+  FOREIGN KEY(household_id) REFERENCES households(id),
+  FOREIGN KEY(product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX idx_product_aliases_name ON product_aliases(household_id, lower(name, 'de_AT'));
+CREATE INDEX idx_product_aliases_product_id ON product_aliases(product_id);
@@ -0,0 +1,23 @@
+CREATE TABLE recipes (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  name TEXT NOT NULL CHECK(length(name) <= 40) COLLATE de_AT,
+  servings INTEGER NOT NULL CHECK(servings > 0 AND servings <= 100),
+  FOREIGN KEY(household_id) REFERENCES households(id)
+);
+
+CREATE UNIQUE INDEX idx_recipes_name ON recipes(household_id, lower(name, 'de_AT'));
+
+CREATE TABLE recipe_ingredients (
+  id INTEGER PRIMARY KEY,
+  recipe_id INTEGER NOT NULL,
+  product_id INTEGER NOT NULL,
+  dimension_id INTEGER NOT NULL,
+  quantity INTEGER NOT NULL CHECK(quantity > 0 AND quantity <= 10000),
+  FOREIGN KEY(recipe_id) REFERENCES recipes(id),
+  FOREIGN KEY(product_id) REFERENCES products(id),
+  FOREIGN KEY(dimension_id) REFERENCES dimensions(id)
+);
+
+CREATE INDEX idx_recipe_ingredients_recipe_id ON recipe_ingredients(recipe_id);
+CREATE INDEX idx_recipe_ingredients_product_id ON recipe_ingredients(product_id);
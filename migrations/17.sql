@@ -0,0 +1,13 @@
+CREATE TABLE stock_levels (
+  id INTEGER PRIMARY KEY,
+  product_id INTEGER NOT NULL,
+  dimension_id INTEGER NOT NULL,
+  quantity INTEGER NOT NULL CHECK(quantity >= 0 AND quantity <= 1000000),
+  minimum_quantity INTEGER CHECK(minimum_quantity IS NULL OR (minimum_quantity > 0 AND minimum_quantity <= 10000)),
+  list_id INTEGER CHECK((minimum_quantity IS NULL) = (list_id IS NULL)),
+  FOREIGN KEY(product_id) REFERENCES products(id),
+  FOREIGN KEY(dimension_id) REFERENCES dimensions(id),
+  FOREIGN KEY(list_id) REFERENCES lists(id)
+);
+
+CREATE UNIQUE INDEX idx_stock_levels ON stock_levels(product_id, dimension_id);
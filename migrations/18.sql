@@ -0,0 +1,16 @@
+CREATE TABLE item_actions (
+  id INTEGER PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  kind TEXT NOT NULL CHECK(kind IN ('do', 'undo', 'redo', 'system')),
+  reverted_action_id INTEGER CHECK((kind IN ('undo', 'redo')) = (reverted_action_id IS NOT NULL)),
+  recorded_at DATETIME NOT NULL,
+  FOREIGN KEY(user_id) REFERENCES users(id),
+  FOREIGN KEY(reverted_action_id) REFERENCES item_actions(id)
+);
+
+CREATE INDEX idx_item_actions_user_id ON item_actions(user_id);
+CREATE UNIQUE INDEX idx_item_actions_reverted_action_id ON item_actions(reverted_action_id);
+
+ALTER TABLE item_changes ADD COLUMN action_id INTEGER REFERENCES item_actions(id);
+
+CREATE INDEX idx_item_changes_action_id ON item_changes(action_id);
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"database/sql"
+	"strings"
+
+	"stravid.com/besserliste/types"
+)
+
+func init() {
+	register(19, "19_index_products.go", indexProducts)
+}
+
+// indexProducts adds the products created before the search index existed to it, SQL cannot split names into trigrams.
+// Products indexed since then are left alone, the application keeps their trigrams up to date.
+func indexProducts(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT products.id, products.name_singular || ' ' || products.name_plural || COALESCE((SELECT ' ' || group_concat(name, ' ') FROM product_aliases WHERE product_aliases.product_id = products.id), '')
+		FROM products
+		WHERE NOT EXISTS (SELECT 1 FROM product_trigrams WHERE product_trigrams.product_id = products.id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int64]string)
+	for rows.Next() {
+		var productId int64
+		var productNames string
+		err = rows.Scan(&productId, &productNames)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		names[productId] = productNames
+	}
+
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	for productId, productNames := range names {
+		for _, trigram := range types.Trigrams(strings.TrimSpace(productNames)) {
+			_, err = tx.Exec(`INSERT INTO product_trigrams (product_id, trigram) VALUES (?, ?) ON CONFLICT DO NOTHING;`, productId, trigram)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+CREATE TABLE lists (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL CHECK(length(name) <= 40) COLLATE de_AT,
+  ordering INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_lists_name ON lists(lower(name, 'de_AT'));
+CREATE UNIQUE INDEX idx_lists_ordering ON lists(ordering);
+
+INSERT INTO lists (id, name, ordering) VALUES (1, 'Supermarkt', 1);
+
+-- Commit outer transaction so we can change the foreign_keys PRAGMA
+COMMIT;
+
+PRAGMA foreign_keys=OFF;
+BEGIN;
+
+CREATE TABLE new_items (
+  id INTEGER PRIMARY KEY,
+  list_id INTEGER NOT NULL,
+  product_id INTEGER NOT NULL,
+  dimension_id INTEGER NOT NULL,
+  quantity INTEGER NOT NULL CHECK(quantity > 0 AND quantity <= 10000),
+  state TEXT NOT NULL CHECK(state IN ('added', 'gathered', 'removed')),
+  changed_at DATETIME NOT NULL,
+  FOREIGN KEY(list_id) REFERENCES lists(id),
+  FOREIGN KEY(product_id) REFERENCES products(id),
+  FOREIGN KEY(dimension_id) REFERENCES dimensions(id)
+);
+INSERT INTO new_items (id, list_id, product_id, dimension_id, quantity, state, changed_at) SELECT id, 1, product_id, dimension_id, quantity, state, changed_at FROM items;
+DROP TABLE items;
+ALTER TABLE new_items RENAME TO items;
+CREATE UNIQUE INDEX idx_items_added ON items(list_id, state, product_id, dimension_id) WHERE state = 'added';
+CREATE INDEX idx_items_changed_at ON items(changed_at);
+
+PRAGMA foreign_key_check;
+COMMIT;
+
+PRAGMA foreign_keys=ON;
+
+-- Begin outer transaction so the migration logic does not break
+BEGIN;
@@ -0,0 +1,74 @@
+CREATE TABLE households (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL CHECK(length(name) <= 40) COLLATE de_AT
+);
+
+CREATE UNIQUE INDEX idx_households_name ON households(lower(name, 'de_AT'));
+
+INSERT INTO households (id, name) VALUES (1, 'Haushalt');
+
+-- Commit outer transaction so we can change the foreign_keys PRAGMA
+COMMIT;
+
+PRAGMA foreign_keys=OFF;
+BEGIN;
+
+CREATE TABLE new_users (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  name TEXT NOT NULL CHECK(length(name) <= 256) COLLATE de_AT,
+  email TEXT NOT NULL CHECK(length(email) <= 32) COLLATE NOCASE,
+  FOREIGN KEY(household_id) REFERENCES households(id)
+);
+INSERT INTO new_users (id, household_id, name, email) SELECT id, 1, name, email FROM users;
+DROP TABLE users;
+ALTER TABLE new_users RENAME TO users;
+CREATE UNIQUE INDEX idx_users_name ON users(household_id, lower(name, 'de_AT'));
+CREATE UNIQUE INDEX idx_users_email ON users(email);
+
+CREATE TABLE new_categories (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  name TEXT NOT NULL CHECK(length(name) <= 20) COLLATE de_AT,
+  ordering INTEGER NOT NULL,
+  FOREIGN KEY(household_id) REFERENCES households(id)
+);
+INSERT INTO new_categories (id, household_id, name, ordering) SELECT id, 1, name, ordering FROM categories;
+DROP TABLE categories;
+ALTER TABLE new_categories RENAME TO categories;
+CREATE UNIQUE INDEX idx_categories_name ON categories(household_id, lower(name, 'de_AT'));
+CREATE UNIQUE INDEX idx_categories_ordering ON categories(household_id, ordering);
+
+CREATE TABLE new_products (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  name_singular TEXT NOT NULL CHECK(length(name_singular) <= 40) COLLATE de_AT,
+  name_plural TEXT NOT NULL CHECK(length(name_plural) <= 40) COLLATE de_AT,
+  FOREIGN KEY(household_id) REFERENCES households(id)
+);
+INSERT INTO new_products (id, household_id, name_singular, name_plural) SELECT id, 1, name_singular, name_plural FROM products;
+DROP TABLE products;
+ALTER TABLE new_products RENAME TO products;
+CREATE UNIQUE INDEX idx_products_name_singular ON products(household_id, lower(name_singular, 'de_AT'));
+CREATE UNIQUE INDEX idx_products_name_plural ON products(household_id, lower(name_plural, 'de_AT'));
+
+CREATE TABLE new_lists (
+  id INTEGER PRIMARY KEY,
+  household_id INTEGER NOT NULL,
+  name TEXT NOT NULL CHECK(length(name) <= 40) COLLATE de_AT,
+  ordering INTEGER NOT NULL,
+  FOREIGN KEY(household_id) REFERENCES households(id)
+);
+INSERT INTO new_lists (id, household_id, name, ordering) SELECT id, 1, name, ordering FROM lists;
+DROP TABLE lists;
+ALTER TABLE new_lists RENAME TO lists;
+CREATE UNIQUE INDEX idx_lists_name ON lists(household_id, lower(name, 'de_AT'));
+CREATE UNIQUE INDEX idx_lists_ordering ON lists(household_id, ordering);
+
+PRAGMA foreign_key_check;
+COMMIT;
+
+PRAGMA foreign_keys=ON;
+
+-- Begin outer transaction so the migration logic does not break
+BEGIN;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN password_hash TEXT CHECK(length(password_hash) = 60);
+
+CREATE TABLE failed_logins (
+  id INTEGER PRIMARY KEY,
+  email TEXT NOT NULL CHECK(length(email) <= 256) COLLATE NOCASE,
+  attempted_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_failed_logins_email ON failed_logins(email, attempted_at);
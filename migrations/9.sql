@@ -0,0 +1,2 @@
+CREATE INDEX idx_item_changes_item_id ON item_changes(item_id);
+CREATE INDEX idx_item_changes_recorded_at ON item_changes(state, recorded_at);
@@ -0,0 +1,24 @@
+package migrations
+
+// releasedChecksums are the checksums of the migrations released before schema_migrations existed.
+// Databases upgraded from such a release record these instead of the checksums of the current files, so files edited in the meantime are still detected.
+var releasedChecksums = map[int]string{
+	1:  "3b3140468e17026e1694147303075261f663b3a04e404a569bbbf916e91191d8",
+	2:  "db45453202002a49cfe15c317c14d086407c8e71ef43c82d159f8c2e745a2d17",
+	3:  "6ee5f84f6e65a6c7c1c8be8f11be724abd32d4ed1a4d4b0ca2f7903a2afd4ad4",
+	4:  "c60a8aeabfe2bc65d64e5ebd24bb3fcd4e2ec0f6fd876f00579c9f5b0e0d2834",
+	5:  "66ddaf1b0063e827cdb722f7901237cb41ea25ba9554a3dd1b4a7be4b9535748",
+	6:  "c90236b7b409a53410232ea2b0567a2ee3eb011d2da92221bc40cc948c72b070",
+	7:  "52de29009568249980b26c87d3c8579de8373b6d7312858790bf67f025268a80",
+	8:  "9d1c5b4196ca3d7b5f71f15320695397fcb17f7a2c8ef4b0be6645a82c6b642a",
+	9:  "3af08ef0b0919e6d163eba6945dea5ffdd98c88fd4dd2415194f007af33e5ae3",
+	10: "f56315418f03f236cb3bc777babe903204110aa83d854ea506ee41c98fd06f31",
+	11: "f24fda22fdffb14c4c3ef582f30850925c7c9db70a62d6abe901475cc0107d8a",
+	12: "ffd77230a2a0dced1b84bb366f1a168de6181a4e3491462d0daccf836cc85e49",
+	13: "3562bb93c7784c387ccd84512f26671647de12893366b0f1a4dd84d639d5c172",
+	14: "e3784cdeb22197a409bf523dd301420b9a160c39e2ced0a534bfa0c07bf7cafd",
+	15: "49b95472d524b36b171eb9be38b331f524be9aa0b144949d6c16ebd338fbc326",
+	16: "edffc9c53de69ae78e4d3cac942c5c5bac94714e0f6261c13f998affda27da17",
+	17: "123f36da6ad1872ff0a283fc1162282622ec903adf0ba694b96d2fbfe7c92a48",
+	18: "11290730a8965758f7d3997a43ceea51fd63aba2f5c137ee58f15a14ec204f9d",
+}
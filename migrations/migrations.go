@@ -1,54 +1,335 @@
 package migrations
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
 	"embed"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 //go:embed *.sql
-var files embed.FS
+var embeddedFiles embed.FS
 
-func Run(db *sql.DB) {
-	version := getVersion(db)
+// files holds the numbered SQL files, tests replace it with their own migrations.
+var files fs.FS = embeddedFiles
 
-	for {
-		version++
-		path := fmt.Sprintf("%v.sql", version)
-		migration, err := files.ReadFile(path)
+// Migration is either a numbered SQL file or a Go function passed to register.
+type Migration struct {
+	Version  int
+	Name     string
+	Checksum string
+	query    string
+	run      func(tx *sql.Tx) error
+}
+
+// Status tells whether a migration was applied and whether its file changed since.
+// AppliedChecksum is empty for pending migrations, databases without schema_migrations are compared against the released checksums.
+type Status struct {
+	Migration
+	Applied         bool
+	AppliedChecksum string
+	AppliedAt       string
+}
+
+func (s *Status) Changed() bool {
+	return s.Applied && s.AppliedChecksum != "" && s.AppliedChecksum != s.Checksum
+}
+
+var goMigrations = map[int]Migration{}
+
+// register adds a migration written in Go for data transformations SQL cannot express, call it from an init function next to the SQL files.
+// Go migrations share the numbering with the SQL files and run in a transaction as well, 19_index_products.go is an example.
+// Their name takes the place of the file content in the checksum, so rename the file of a Go migration whenever it changes.
+func register(version int, name string, run func(tx *sql.Tx) error) {
+	goMigrations[version] = Migration{Version: version, Name: name, Checksum: checksum("go:" + name), run: run}
+}
 
+// All returns every known migration ordered by version, versions have to be continuous starting at 1.
+func All() ([]Migration, error) {
+	byVersion := make(map[int]Migration)
+
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".sql"))
 		if err != nil {
-			break
+			return nil, fmt.Errorf("Migration `migrations/%s` is not named after its version", entry.Name())
+		}
+
+		query, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		byVersion[version] = Migration{Version: version, Name: entry.Name(), Checksum: checksum(string(query)), query: string(query)}
+	}
+
+	for version, migration := range goMigrations {
+		if _, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("Migration %d exists as SQL file and as Go migration `%s`", version, migration.Name)
+		}
+
+		byVersion[version] = migration
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for index, migration := range migrations {
+		if migration.Version != index+1 {
+			return nil, fmt.Errorf("Migration %d is missing", index+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Statuses compares all known migrations with the ones recorded in the database.
+func Statuses(db *sql.DB) ([]Status, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := getVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything up to PRAGMA user_version is applied, even if the database was not migrated since checksums exist.
+	applied := make(map[int]Status)
+	for v := 1; v <= version; v++ {
+		applied[v] = Status{Applied: true, AppliedChecksum: releasedChecksums[v]}
+	}
+
+	// Status and dry runs must not change the database, so the table of applied migrations may not exist yet.
+	var tables int
+	err = db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations';`).Scan(&tables)
+	if err != nil {
+		return nil, err
+	}
+
+	if tables > 0 {
+		err = readApplied(db, applied)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		query := fmt.Sprintf(`
-			BEGIN;
-			%v
-			PRAGMA user_version = %v;
-			COMMIT;
-		`,
-			string(migration),
-			version)
+	statuses := []Status{}
+	for _, migration := range migrations {
+		s := applied[migration.Version]
+		s.Migration = migration
+		statuses = append(statuses, s)
+	}
 
-		_, err = db.Exec(query)
+	return statuses, nil
+}
 
+func readApplied(db *sql.DB, applied map[int]Status) error {
+	rows, err := db.Query(`SELECT version, checksum, COALESCE(applied_at, '') FROM schema_migrations;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var s Status
+		err = rows.Scan(&version, &s.AppliedChecksum, &s.AppliedAt)
 		if err != nil {
-			log.Panicln(fmt.Sprintf("Migration `migrations/%v` failed:", path), err.Error())
+			return err
 		}
 
-		log.Println(fmt.Sprintf("Ran migration %v", path))
+		s.Applied = true
+		applied[version] = s
 	}
+
+	return rows.Err()
 }
 
-func getVersion(db *sql.DB) int {
+// Pending returns the migrations Run would apply.
+// Changed files of applied migrations are an error, the database might not have the schema the files describe.
+func Pending(db *sql.DB) ([]Migration, error) {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, s := range statuses {
+		if s.Changed() {
+			return nil, fmt.Errorf("Migration `migrations/%s` changed after it was applied, restore the file or check the database by hand", s.Name)
+		}
+
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Run applies all pending migrations, each in its own transaction together with its checksum and PRAGMA user_version.
+// beforeMigrating runs once before the first pending migration of an existing database, it returns the path of the backup it took.
+// Callers which already have a backup pass nil.
+// The first failing migration stops Run, the migrations before it stay applied.
+func Run(db *sql.DB, beforeMigrating func(version int) (string, error)) error {
+	migrations, err := All()
+	if err != nil {
+		return err
+	}
+
+	err = recordChecksums(db, migrations)
+	if err != nil {
+		return err
+	}
+
+	pending, err := Pending(db)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	backupPath := ""
+	if pending[0].Version > 1 && beforeMigrating != nil {
+		backupPath, err = beforeMigrating(pending[0].Version - 1)
+		if err != nil {
+			return fmt.Errorf("Backup before migrating failed: %w", err)
+		}
+
+		log.Println(fmt.Sprintf("Backed up database to %s before migrating", backupPath))
+	}
+
+	// Some migrations commit the transaction themselves to change PRAGMA foreign_keys, which only applies to their own connection.
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, migration := range pending {
+		err = apply(ctx, conn, migration)
+		if err != nil {
+			if backupPath != "" {
+				return fmt.Errorf("Migration `migrations/%s` failed, the database from before migrating is at %s: %w", migration.Name, backupPath, err)
+			}
+			return fmt.Errorf("Migration `migrations/%s` failed: %w", migration.Name, err)
+		}
+
+		log.Println(fmt.Sprintf("Ran migration %v", migration.Name))
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	record := fmt.Sprintf(`
+		INSERT INTO schema_migrations (version, checksum, applied_at) VALUES (%d, '%s', datetime('now'));
+		PRAGMA user_version = %d;
+	`, migration.Version, migration.Checksum, migration.Version)
+
+	if migration.run != nil {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		err = migration.run(tx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(record)
+		if err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}
+
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("BEGIN;\n%s\n%s\nCOMMIT;", migration.query, record))
+	if err != nil {
+		// A failing statement leaves the transaction open on this connection.
+		conn.ExecContext(ctx, "ROLLBACK;")
+		return err
+	}
+
+	return nil
+}
+
+// recordChecksums creates the table of applied migrations.
+// Databases migrated before it existed only know their PRAGMA user_version, their migrations are recorded with the released checksums.
+func recordChecksums(db *sql.DB, migrations []Migration) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			checksum TEXT NOT NULL,
+			applied_at DATETIME
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	version, err := getVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.Version > version {
+			break
+		}
+
+		checksum, ok := releasedChecksums[migration.Version]
+		if !ok {
+			checksum = migration.Checksum
+		}
+
+		_, err = db.Exec(`INSERT INTO schema_migrations (version, checksum) VALUES (?, ?) ON CONFLICT (version) DO NOTHING;`, migration.Version, checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getVersion(db *sql.DB) (int, error) {
 	row := db.QueryRow(`PRAGMA user_version;`)
 	var version int
 
 	err := row.Scan(&version)
 	if err != nil {
-		log.Panicln("Cannot get database user version", err)
+		return 0, fmt.Errorf("Cannot get database user version: %w", err)
 	}
 
-	return version
+	return version, nil
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
 }
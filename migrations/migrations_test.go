@@ -0,0 +1,318 @@
+package migrations
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// useTestMigrations replaces the SQL files, Go migrations and released checksums for the duration of the test.
+func useTestMigrations(t *testing.T, queries map[string]string) fstest.MapFS {
+	testFiles := fstest.MapFS{}
+	for name, query := range queries {
+		testFiles[name] = &fstest.MapFile{Data: []byte(query)}
+	}
+
+	previousFiles, previousGoMigrations, previousReleasedChecksums := files, goMigrations, releasedChecksums
+	files, goMigrations, releasedChecksums = testFiles, map[int]Migration{}, map[int]string{}
+	t.Cleanup(func() {
+		files, goMigrations, releasedChecksums = previousFiles, previousGoMigrations, previousReleasedChecksums
+	})
+
+	return testFiles
+}
+
+func openTestDatabase(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "besserliste.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func countAppliedMigrations(t *testing.T, db *sql.DB) int {
+	var count int
+	err := db.QueryRow(`SELECT count(*) FROM schema_migrations;`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func TestRunPendingSqlMigration(t *testing.T) {
+	testFiles := useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);",
+	})
+	db := openTestDatabase(t)
+
+	backups := []int{}
+	backup := func(version int) (string, error) {
+		backups = append(backups, version)
+		return "backup.db", nil
+	}
+
+	err := Run(db, backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fresh databases have nothing worth backing up.
+	if len(backups) != 0 {
+		t.Fatalf("%v instead of no backups", backups)
+	}
+
+	testFiles["2.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE products ADD COLUMN note TEXT NOT NULL DEFAULT '';")}
+
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 1 || pending[0].Name != "2.sql" {
+		t.Fatalf("%v instead of 2.sql", pending)
+	}
+
+	err = Run(db, backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != 1 || backups[0] != 1 {
+		t.Fatalf("%v instead of a backup of version 1", backups)
+	}
+
+	version, err := getVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 2 {
+		t.Fatalf("%d instead of %d", version, 2)
+	}
+
+	_, err = db.Exec(`INSERT INTO products (name, note) VALUES ('Tomate', 'rot');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunFailingMigration(t *testing.T) {
+	useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);",
+		"2.sql": "CREATE TABLE stores (id INTEGER PRIMARY KEY); INSERT INTO missing VALUES (1);",
+	})
+	db := openTestDatabase(t)
+
+	err := Run(db, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "Migration `migrations/2.sql` failed") {
+		t.Fatalf("%v instead of the failing migration", err)
+	}
+
+	version, err := getVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 1 {
+		t.Fatalf("%d instead of %d", version, 1)
+	}
+
+	var tables int
+	err = db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE name = 'stores';`).Scan(&tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tables != 0 {
+		t.Fatal("stores table of the failed migration was not rolled back")
+	}
+}
+
+func TestRunGoMigration(t *testing.T) {
+	useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL); INSERT INTO products (name) VALUES ('tomate'), ('gurke');",
+	})
+	register(2, "capitalize product names", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE products SET name = upper(substr(name, 1, 1)) || substr(name, 2);`)
+		return err
+	})
+	db := openTestDatabase(t)
+
+	err := Run(db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names string
+	err = db.QueryRow(`SELECT group_concat(name, ',') FROM (SELECT name FROM products ORDER BY id);`).Scan(&names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names != "Tomate,Gurke" {
+		t.Fatalf("%s instead of %s", names, "Tomate,Gurke")
+	}
+
+	statuses, err := Statuses(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(statuses) != 2 || !statuses[1].Applied || statuses[1].AppliedChecksum != checksum("go:capitalize product names") {
+		t.Fatalf("%+v instead of an applied Go migration", statuses)
+	}
+}
+
+func TestRunRejectsChangedMigration(t *testing.T) {
+	testFiles := useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);",
+	})
+	db := openTestDatabase(t)
+
+	err := Run(db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFiles["1.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);")}
+	testFiles["2.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE stores (id INTEGER PRIMARY KEY);")}
+
+	statuses, err := Statuses(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !statuses[0].Changed() || statuses[1].Changed() {
+		t.Fatalf("%+v instead of only 1.sql changed", statuses)
+	}
+
+	expected := "Migration `migrations/1.sql` changed after it was applied"
+	err = Run(db, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("%v instead of %s", err, expected)
+	}
+
+	// Changed migrations stop Run before anything else is applied.
+	if count := countAppliedMigrations(t, db); count != 1 {
+		t.Fatalf("%d instead of %d applied migrations", count, 1)
+	}
+}
+
+func TestDryRunLeavesDatabaseUnchanged(t *testing.T) {
+	testFiles := useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);",
+	})
+	db := openTestDatabase(t)
+
+	err := Run(db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFiles["2.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE stores (id INTEGER PRIMARY KEY);")}
+
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 1 {
+		t.Fatalf("%d instead of %d pending migrations", len(pending), 1)
+	}
+
+	version, err := getVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 1 {
+		t.Fatalf("%d instead of %d", version, 1)
+	}
+
+	if count := countAppliedMigrations(t, db); count != 1 {
+		t.Fatalf("%d instead of %d applied migrations", count, 1)
+	}
+}
+
+func TestDryRunBeforeChecksums(t *testing.T) {
+	useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);",
+		"2.sql": "CREATE TABLE stores (id INTEGER PRIMARY KEY);",
+	})
+	db := openTestDatabase(t)
+
+	// Databases migrated before checksums existed only know their PRAGMA user_version.
+	_, err := db.Exec(`CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL); PRAGMA user_version = 1;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 1 || pending[0].Name != "2.sql" {
+		t.Fatalf("%v instead of 2.sql", pending)
+	}
+
+	var tables int
+	err = db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE name = 'schema_migrations';`).Scan(&tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tables != 0 {
+		t.Fatal("schema_migrations was created by a dry run")
+	}
+}
+
+func TestReleasedChecksums(t *testing.T) {
+	migrations, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, migration := range migrations {
+		released, ok := releasedChecksums[migration.Version]
+		if ok && released != migration.Checksum {
+			t.Fatalf("migrations/%s changed after it was released", migration.Name)
+		}
+	}
+}
+
+func TestRunRejectsMigrationChangedBeforeChecksums(t *testing.T) {
+	useTestMigrations(t, map[string]string{
+		"1.sql": "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);",
+	})
+	releasedChecksums[1] = checksum("CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);")
+	db := openTestDatabase(t)
+
+	// The database was migrated by a release before checksums existed, the file was edited since.
+	_, err := db.Exec(`CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL); PRAGMA user_version = 1;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := Statuses(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !statuses[0].Changed() {
+		t.Fatalf("%+v instead of 1.sql changed", statuses)
+	}
+
+	expected := "Migration `migrations/1.sql` changed after it was applied"
+	err = Run(db, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("%v instead of %s", err, expected)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+
+	"stravid.com/besserliste/types"
+)
+
+// stockItem keeps the pantry in step with the shopping list.
+// Checking an item puts its quantity into stock, undoing the check takes it out again.
+func (env *Environment) stockItem(tx *sql.Tx, item *types.SelectedItem, oldState string, newState string) error {
+	if oldState == "added" && newState == "gathered" {
+		return env.queries.AddToStockLevel(tx, item.ProductId, item.Dimension.Id, int64(item.Quantity))
+	} else if oldState == "gathered" && newState == "added" {
+		return env.queries.RemoveFromStockLevel(tx, item.ProductId, item.Dimension.Id, int64(item.Quantity))
+	}
+
+	return nil
+}
+
+// consumeStock takes the amount out of stock and refills the product if it fell below its minimum.
+// Consuming more than there is empties the stock, the pantry is only as exact as what gets recorded.
+func (env *Environment) consumeStock(tx *sql.Tx, householdId int, userId int, stockLevel *types.StockLevel, unitId string, amount string, formErrors map[string]string) ([]itemEvent, error) {
+	unit, ok := findDimensionUnit(stockLevel.Dimension, unitId)
+	if !ok {
+		formErrors["unit_id"] = "Maßeinheit wählen"
+	}
+
+	parsedQuantity := parseQuantity(amount, formErrors)
+	if len(formErrors) != 0 {
+		return nil, nil
+	}
+
+	baseQuantity := toBaseQuantity(parsedQuantity, unit, 0, formErrors)
+	if len(formErrors) != 0 {
+		return nil, nil
+	}
+
+	err := env.queries.RemoveFromStockLevel(tx, stockLevel.ProductId, stockLevel.Dimension.Id, baseQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return env.refillStockLevel(tx, householdId, userId, stockLevel.Id)
+}
+
+// setStockMinimum configures the quantity below which the product goes on the list, an empty amount turns that off.
+func (env *Environment) setStockMinimum(tx *sql.Tx, householdId int, userId int, listId int, stockLevel *types.StockLevel, unitId string, amount string, formErrors map[string]string) ([]itemEvent, error) {
+	if amount == "" {
+		return nil, env.queries.SetStockLevelMinimum(tx, stockLevel.Id, 0, 0)
+	}
+
+	unit, ok := findDimensionUnit(stockLevel.Dimension, unitId)
+	if !ok {
+		formErrors["unit_id"] = "Maßeinheit wählen"
+	}
+
+	parsedQuantity := parseQuantity(amount, formErrors)
+	if len(formErrors) != 0 {
+		return nil, nil
+	}
+
+	baseQuantity := toBaseQuantity(parsedQuantity, unit, 0, formErrors)
+	if len(formErrors) != 0 {
+		return nil, nil
+	}
+
+	err := env.queries.SetStockLevelMinimum(tx, stockLevel.Id, baseQuantity, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	return env.refillStockLevel(tx, householdId, userId, stockLevel.Id)
+}
+
+// refillStockLevel puts the quantity missing to the minimum on the configured list.
+// Nothing is added while the product is already on that list, otherwise every consumption would raise the quantity again.
+func (env *Environment) refillStockLevel(tx *sql.Tx, householdId int, userId int, stockLevelId int) ([]itemEvent, error) {
+	stockLevel, err := env.queries.GetStockLevel(tx, householdId, stockLevelId)
+	if err != nil {
+		return nil, err
+	}
+
+	missingQuantity := stockLevel.MissingQuantity()
+	if missingQuantity == 0 {
+		return nil, nil
+	}
+
+	_, err = env.queries.GetAddedItemByProductDimension(tx, stockLevel.ListId, stockLevel.ProductId, stockLevel.Dimension.Id)
+	if err == nil {
+		return nil, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	itemId, err := env.addBaseQuantity(tx, newItemAction(userId, "do"), stockLevel.ListId, stockLevel.ProductId, stockLevel.Dimension.Id, int64(missingQuantity), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return []itemEvent{{ListId: stockLevel.ListId, ItemId: int(itemId), State: "added", UserId: userId}}, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"stravid.com/besserliste/types"
+)
+
+// After this many failed attempts within 15 minutes an email address cannot log in until the window has passed.
+const maxFailedLogins = 5
+
+var errInvalidCredentials = errors.New("E-Mail-Adresse oder Passwort inkorrekt")
+var errTooManyFailedLogins = errors.New("Zu viele fehlgeschlagene Anmeldungen, in 15 Minuten erneut versuchen")
+
+// Compared against when the email address is unknown so the response takes as long as for a wrong password.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("besserliste"), bcrypt.DefaultCost)
+
+// verifyLogin returns the user with the given credentials and records failed attempts.
+// The transaction has to be committed even when errInvalidCredentials is returned, otherwise the attempt is not counted.
+func (env *Environment) verifyLogin(tx *sql.Tx, email string, password string) (*types.User, error) {
+	failedLogins, err := env.queries.CountRecentFailedLogins(tx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if failedLogins >= maxFailedLogins {
+		return nil, errTooManyFailedLogins
+	}
+
+	user, err := env.queries.GetUserByEmail(tx, email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+	} else {
+		matches, err := env.passwordMatches(tx, user.Id, password)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			err = env.queries.RemoveFailedLogins(tx, email)
+			if err != nil {
+				return nil, err
+			}
+
+			return user, nil
+		}
+	}
+
+	err = env.queries.InsertFailedLogin(tx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, errInvalidCredentials
+}
+
+// passwordMatches checks the password against the stored hash.
+// Users without a hash cannot log in until they get a password through `besserliste set-password`.
+func (env *Environment) passwordMatches(tx *sql.Tx, userId int, password string) (bool, error) {
+	passwordHash, err := env.queries.GetPasswordHash(tx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	if !passwordHash.Valid {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return false, nil
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(passwordHash.String), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (env *Environment) setPassword(tx *sql.Tx, userId int, password string) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return env.queries.SetPasswordHash(tx, userId, string(passwordHash))
+}
+
+func validateNewPassword(password string, confirmation string, formErrors map[string]string) {
+	if len(password) < 8 {
+		formErrors["new_password"] = "Längeres Passwort angeben (mindestens 8 Zeichen)"
+	} else if len(password) > 72 {
+		// bcrypt ignores everything after the first 72 bytes.
+		formErrors["new_password"] = "Kürzeres Passwort angeben (maximal 72 Zeichen)"
+	} else if password != confirmation {
+		formErrors["new_password_confirmation"] = "Passwort wiederholen"
+	}
+}
+
+func (env *Environment) failedLoginsCleaner(ctx context.Context) {
+	for {
+		tx, err := env.db.Begin()
+		if err != nil {
+			panic(fmt.Sprintf("failedLoginsCleaner: %v", err))
+		}
+
+		_, err = env.queries.RemovePreviousFailedLogins(tx)
+
+		if err != nil {
+			tx.Rollback()
+			panic(fmt.Sprintf("failedLoginsCleaner: %v", err))
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				panic(fmt.Sprintf("failedLoginsCleaner: %v", err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(60 * 60 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"stravid.com/besserliste/types"
+)
+
+// Matches the check on `item_changes.price`, prices are stored in cents.
+const maxItemPrice = 1000000
+
+var pricePattern = regexp.MustCompile(`^[0-9]{1,6}([.,][0-9]{1,2})?$`)
+
+// parsePrice turns amounts like `2,49` or `2.5` into cents.
+func parsePrice(amount string, formErrors map[string]string) int {
+	if amount == "" {
+		formErrors["price"] = "Preis angeben"
+		return 0
+	}
+
+	if !pricePattern.MatchString(amount) {
+		formErrors["price"] = "Preis in Euro angeben (zum Beispiel 2,49)"
+		return 0
+	}
+
+	parsedPrice, err := strconv.ParseFloat(strings.Replace(amount, ",", ".", -1), 64)
+	if err != nil {
+		formErrors["price"] = "Preis in Euro angeben (zum Beispiel 2,49)"
+		return 0
+	}
+
+	return int(math.Round(parsedPrice * 100))
+}
+
+// itemPrice is the total price of the item in cents.
+// An empty unitId means the amount already is the total, otherwise it is the price per unit and multiplied by the quantity of the item.
+func itemPrice(item *types.SelectedItem, amount string, unitId string, formErrors map[string]string) int {
+	parsedPrice := parsePrice(amount, formErrors)
+	if len(formErrors) != 0 {
+		return 0
+	}
+
+	price := parsedPrice
+	if unitId != "" {
+		unit, ok := findDimensionUnit(item.Dimension, unitId)
+		if !ok {
+			formErrors["price_unit_id"] = "Gesamtpreis oder Maßeinheit wählen"
+			return 0
+		}
+
+		price = int(math.Round(float64(parsedPrice) * float64(item.Quantity) * unit.ConversionFromBase))
+	}
+
+	if price < 1 {
+		formErrors["price"] = "Höheren Preis angeben (mindestens 0,01 € insgesamt)"
+	} else if price > maxItemPrice {
+		formErrors["price"] = "Niedrigeren Preis angeben (höchstens 10000,00 € insgesamt)"
+	}
+
+	return price
+}
+
+func findDimensionUnit(dimension types.Dimension, unitId string) (types.Unit, bool) {
+	for _, unit := range dimension.Units {
+		if strconv.Itoa(unit.Id) == unitId {
+			return unit, true
+		}
+	}
+
+	return types.Unit{}, false
+}
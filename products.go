@@ -0,0 +1,346 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+
+	"stravid.com/besserliste/types"
+)
+
+var errProductInUse = errors.New("Produkt wurde bereits aufgeschrieben oder wird in einem Rezept verwendet, stattdessen mit einem anderen Produkt zusammenführen.")
+
+// addProduct creates a product with its categories and dimensions.
+// Invalid input is reported through formErrors and leaves the catalogue untouched.
+func (env *Environment) addProduct(tx *sql.Tx, householdId int, userId int, nameSingular string, namePlural string, categoryIds []string, dimensionIds []string, formErrors map[string]string) (int64, error) {
+	err := env.validateProduct(tx, householdId, nameSingular, namePlural, categoryIds, dimensionIds, formErrors)
+	if err != nil || len(formErrors) != 0 {
+		return 0, err
+	}
+
+	result, err := env.queries.InsertProduct(tx, householdId, nameSingular, namePlural)
+	if err != nil {
+		if isDuplicateProductName(err, formErrors) {
+			return 0, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	productId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range dimensionIds {
+		_, err = env.queries.InsertProductDimension(tx, productId, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, id := range categoryIds {
+		_, err = env.queries.InsertProductCategory(tx, productId, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = env.indexProduct(tx, productId, nameSingular, namePlural)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = env.queries.InsertProductChange(tx, productId, userId, nameSingular, namePlural)
+	if err != nil {
+		return 0, err
+	}
+
+	return productId, nil
+}
+
+// editProduct replaces names, categories and dimensions of a product and records the change.
+// Dimensions which are still used by added or recurring items cannot be removed, otherwise their quantities could no longer be displayed.
+func (env *Environment) editProduct(tx *sql.Tx, householdId int, userId int, productId int, nameSingular string, namePlural string, categoryIds []string, dimensionIds []string, formErrors map[string]string) error {
+	err := env.validateProduct(tx, householdId, nameSingular, namePlural, categoryIds, dimensionIds, formErrors)
+	if err != nil {
+		return err
+	}
+
+	usedDimensionIds, err := env.queries.GetUsedProductDimensions(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	selectedDimensions := map[string]bool{}
+	for _, id := range dimensionIds {
+		selectedDimensions[id] = true
+	}
+
+	for _, id := range usedDimensionIds {
+		if !selectedDimensions[strconv.Itoa(id)] {
+			formErrors["dimension_ids"] = "Größenordnungen behalten, die bereits auf einer Liste verwendet werden"
+		}
+	}
+
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	err = env.queries.UpdateProduct(tx, householdId, productId, nameSingular, namePlural)
+	if err != nil {
+		if isDuplicateProductName(err, formErrors) {
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	err = env.queries.RemoveProductDimensions(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range dimensionIds {
+		_, err = env.queries.InsertProductDimension(tx, int64(productId), id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = env.queries.RemoveProductCategories(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range categoryIds {
+		_, err = env.queries.InsertProductCategory(tx, int64(productId), id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = env.indexProduct(tx, int64(productId), nameSingular, namePlural)
+	if err != nil {
+		return err
+	}
+
+	_, err = env.queries.InsertProductChange(tx, int64(productId), userId, nameSingular, namePlural)
+	return err
+}
+
+// mergeProducts moves items, recurring items, aliases and the change history of one product to another and deletes the merged product.
+// The names of the merged product stay around as aliases of the surviving one.
+// Added items which would end up twice on the same list are summed into the item of the surviving product.
+// The returned events must be published after the transaction is committed.
+func (env *Environment) mergeProducts(tx *sql.Tx, householdId int, userId int, mergedProduct *types.ProductDetails, survivingProduct *types.ProductDetails) ([]itemEvent, error) {
+	// Merges are not undone step by step, their changes only show up as conflicts for undoing earlier actions.
+	action := newItemAction(userId, "system")
+	events := []itemEvent{}
+
+	collidingItems, err := env.queries.GetCollidingAddedItems(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range collidingItems {
+		err = env.queries.SetItemState(tx, item.Id, "removed")
+		if err != nil {
+			return nil, err
+		}
+
+		err = env.recordItemChange(tx, action, int64(item.Id), item.DimensionId, int64(item.Quantity), item.Note, "removed")
+		if err != nil {
+			return nil, err
+		}
+
+		itemId, err := env.addBaseQuantity(tx, action, item.ListId, survivingProduct.Id, item.DimensionId, int64(item.Quantity), item.Note)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, itemEvent{ListId: item.ListId, ItemId: item.Id, State: "removed", UserId: userId})
+		events = append(events, itemEvent{ListId: item.ListId, ItemId: int(itemId), State: "added", UserId: userId})
+	}
+
+	// The surviving product needs every dimension the moved items, recurring items, recipe ingredients and stock levels are measured in.
+	err = env.queries.CopyProductDimensions(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.queries.MoveItems(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.queries.MoveRecurringItems(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.queries.MoveStockLevels(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.queries.MoveRecipeIngredients(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.queries.MoveProductChanges(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.queries.MoveProductAliases(tx, mergedProduct.Id, survivingProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.deleteProduct(tx, householdId, mergedProduct.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = env.keepNamesAsAliases(tx, householdId, mergedProduct, survivingProduct)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// removeProduct deletes a product which was never put on a list.
+// Products with items have a history worth keeping, those can only be merged into another product.
+func (env *Environment) removeProduct(tx *sql.Tx, householdId int, product *types.ProductDetails) error {
+	usage, err := env.queries.CountProductUsage(tx, product.Id)
+	if err != nil {
+		return err
+	}
+
+	if usage != 0 {
+		return errProductInUse
+	}
+
+	err = env.queries.RemoveProductChanges(tx, product.Id)
+	if err != nil {
+		return err
+	}
+
+	return env.deleteProduct(tx, householdId, product.Id)
+}
+
+func (env *Environment) deleteProduct(tx *sql.Tx, householdId int, productId int) error {
+	err := env.queries.RemoveProductTrigrams(tx, int64(productId))
+	if err != nil {
+		return err
+	}
+
+	err = env.queries.RemoveProductAliases(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	// Merging copies the stock levels to the surviving product beforehand, the rows of the merged product are left behind.
+	err = env.queries.RemoveStockLevels(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	err = env.queries.RemoveProductCategories(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	err = env.queries.RemoveProductDimensions(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	return env.queries.RemoveProduct(tx, householdId, productId)
+}
+
+func isDuplicateProductName(err error, formErrors map[string]string) bool {
+	if err.Error() == "UNIQUE constraint failed: index 'idx_products_name_singular'" {
+		formErrors["name_singular"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+		return true
+	} else if err.Error() == "UNIQUE constraint failed: index 'idx_products_name_plural'" {
+		formErrors["name_plural"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+		return true
+	}
+
+	return false
+}
+
+func (env *Environment) validateProduct(tx *sql.Tx, householdId int, nameSingular string, namePlural string, categoryIds []string, dimensionIds []string, formErrors map[string]string) error {
+	categories, err := env.queries.GetCategories(tx, householdId)
+	if err != nil {
+		return err
+	}
+
+	dimensions, err := env.queries.GetDimensions(tx)
+	if err != nil {
+		return err
+	}
+
+	categorySet := map[string]bool{}
+	for _, category := range categories {
+		categorySet[strconv.Itoa(category.Id)] = true
+	}
+
+	dimensionSet := map[string]bool{}
+	for _, dimension := range dimensions {
+		dimensionSet[strconv.Itoa(dimension.Id)] = true
+	}
+
+	if nameSingular == "" {
+		formErrors["name_singular"] = "Namen angeben"
+	} else if utf8.RuneCountInString(nameSingular) > 40 {
+		formErrors["name_singular"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
+	}
+
+	if namePlural == "" {
+		formErrors["name_plural"] = "Namen angeben"
+	} else if utf8.RuneCountInString(namePlural) > 40 {
+		formErrors["name_plural"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
+	}
+
+	// Names already used as alias would otherwise stand for two products.
+	for field, name := range map[string]string{"name_singular": nameSingular, "name_plural": namePlural} {
+		if _, ok := formErrors[field]; ok {
+			continue
+		}
+
+		_, err := env.queries.GetProductAliasByName(tx, householdId, name)
+		if err == nil {
+			formErrors[field] = "Anderen Namen angeben (ist bereits als Synonym eingetragen)"
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	if len(categoryIds) == 0 {
+		formErrors["category_ids"] = "Kategorie wählen"
+	}
+
+	for _, categoryId := range categoryIds {
+		if !categorySet[categoryId] {
+			formErrors["category_ids"] = "Kategorie wählen"
+		}
+	}
+
+	if len(dimensionIds) == 0 {
+		formErrors["dimension_ids"] = "Größenordnung wählen"
+	}
+
+	for _, dimensionId := range dimensionIds {
+		if !dimensionSet[dimensionId] {
+			formErrors["dimension_ids"] = "Größenordnung wählen"
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"stravid.com/besserliste/migrations"
+	"stravid.com/besserliste/queries"
+)
+
+// newTestEnvironment migrates a fresh database in a temporary directory.
+func newTestEnvironment(t *testing.T) *Environment {
+	db, err := sql.Open(databaseDriver, fmt.Sprintf("%s?_foreign_keys=on", filepath.Join(t.TempDir(), "besserliste.db")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Without the build tags from docs/development.txt SQLite lacks ICU and the collation cannot be loaded.
+	err = db.Ping()
+	if err != nil && strings.Contains(err.Error(), "no such function: icu_load_collation") {
+		t.Skip("SQLite was built without ICU, run the tests with -tags \"sqlite_omit_load_extension sqlite_json1 sqlite_icu\"")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrations.Run(db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Environment{queries: queries.Build(db), db: db, events: newEventBroker()}
+}
+
+func addTestProduct(t *testing.T, env *Environment, tx *sql.Tx, householdId int, userId int, nameSingular string, namePlural string) int {
+	categories, err := env.queries.GetCategories(tx, householdId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formErrors := make(map[string]string)
+	productId, err := env.addProduct(tx, householdId, userId, nameSingular, namePlural, []string{strconv.Itoa(categories[0].Id)}, []string{"1"}, formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(formErrors) != 0 {
+		t.Fatalf("%v instead of no form errors", formErrors)
+	}
+
+	return int(productId)
+}
+
+func TestMergeProductsWithStock(t *testing.T) {
+	env := newTestEnvironment(t)
+
+	householdId, userId, err := env.addHousehold("Test", "Test", "test@example.com", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	mergedId := addTestProduct(t, env, tx, int(householdId), int(userId), "Paradeiser", "Paradeiser")
+	survivingId := addTestProduct(t, env, tx, int(householdId), int(userId), "Tomate", "Tomaten")
+
+	for productId, quantity := range map[int]int64{mergedId: 3, survivingId: 2} {
+		err = env.queries.AddToStockLevel(tx, productId, 1, quantity)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mergedProduct, err := env.queries.GetProductDetails(tx, int(householdId), mergedId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	survivingProduct, err := env.queries.GetProductDetails(tx, int(householdId), survivingId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.mergeProducts(tx, int(householdId), int(userId), mergedProduct, survivingProduct)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stockLevels, err := env.queries.GetStockLevels(tx, int(householdId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stockLevels) != 1 {
+		t.Fatalf("%d instead of %d stock levels", len(stockLevels), 1)
+	}
+
+	if stockLevels[0].ProductId != survivingId || stockLevels[0].Quantity != 5 {
+		t.Fatalf("%d of product %d instead of %d of product %d", stockLevels[0].Quantity, stockLevels[0].ProductId, 5, survivingId)
+	}
+}
+
+func TestEditProductKeepsStock(t *testing.T) {
+	env := newTestEnvironment(t)
+
+	householdId, userId, err := env.addHousehold("Test", "Test", "test@example.com", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := env.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	productId := addTestProduct(t, env, tx, int(householdId), int(userId), "Tomate", "Tomaten")
+
+	err = env.queries.AddToStockLevel(tx, productId, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categories, err := env.queries.GetCategories(tx, int(householdId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formErrors := make(map[string]string)
+	err = env.editProduct(tx, int(householdId), int(userId), productId, "Tomate", "Tomaten", []string{strconv.Itoa(categories[1].Id)}, []string{"1"}, formErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(formErrors) != 0 {
+		t.Fatalf("%v instead of no form errors", formErrors)
+	}
+
+	stockLevels, err := env.queries.GetStockLevels(tx, int(householdId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stockLevels) != 1 || stockLevels[0].Quantity != 3 {
+		t.Fatalf("%v instead of a stock of %d", stockLevels, 3)
+	}
+}
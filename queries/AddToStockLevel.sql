@@ -0,0 +1,2 @@
+INSERT INTO stock_levels (product_id, dimension_id, quantity) VALUES (?1, ?2, min(?3, 1000000))
+ON CONFLICT(product_id, dimension_id) DO UPDATE SET quantity = min(quantity + ?3, 1000000);
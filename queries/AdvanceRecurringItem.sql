@@ -0,0 +1,7 @@
+-- Skip every interval missed while the server was down so an item is not added several times at once.
+UPDATE recurring_items
+SET next_due_at = datetime(
+  next_due_at,
+  '+' || (interval_days * (CAST(julianday('now') - julianday(next_due_at) AS INTEGER) / interval_days + 1)) || ' days'
+)
+WHERE id = ?;
@@ -0,0 +1,3 @@
+INSERT INTO dimensions_products (dimension_id, product_id)
+SELECT dimension_id, ?2 FROM dimensions_products WHERE product_id = ?1
+ON CONFLICT (dimensions_products.dimension_id, dimensions_products.product_id) DO NOTHING;
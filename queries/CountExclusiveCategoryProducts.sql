@@ -0,0 +1,6 @@
+SELECT count(*)
+FROM categories_products
+WHERE category_id = ?1 AND NOT EXISTS (
+  SELECT 1 FROM categories_products AS others
+  WHERE others.product_id = categories_products.product_id AND others.category_id <> ?1
+);
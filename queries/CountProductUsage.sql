@@ -0,0 +1,4 @@
+SELECT
+  (SELECT count(*) FROM items WHERE product_id = ?1) +
+  (SELECT count(*) FROM recurring_items WHERE product_id = ?1) +
+  (SELECT count(*) FROM recipe_ingredients WHERE product_id = ?1);
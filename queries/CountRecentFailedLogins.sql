@@ -0,0 +1 @@
+SELECT COUNT(*) FROM failed_logins WHERE email = ? AND attempted_at > datetime('now', '-15 minutes');
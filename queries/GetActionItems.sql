@@ -0,0 +1,33 @@
+-- Every item the action changed with how it looked before the first change of the action.
+-- Items the action put on the list have no earlier change, they are restored as removed.
+-- Later changes by anyone but the user or outside of the undo stack of the user are conflicts, the latest one is returned.
+WITH action_items AS (
+  SELECT item_id, min(id) AS first_change_id, max(id) AS last_change_id
+  FROM item_changes
+  WHERE action_id = ?1
+  GROUP BY item_id
+)
+
+SELECT
+  action_items.item_id,
+  COALESCE(previous_changes.state, 'removed'),
+  COALESCE(previous_changes.dimension_id, first_changes.dimension_id),
+  COALESCE(previous_changes.quantity, first_changes.quantity),
+  COALESCE(previous_changes.note, first_changes.note),
+  COALESCE(conflicting_users.name, '')
+FROM action_items
+INNER JOIN item_changes AS first_changes ON first_changes.id = action_items.first_change_id
+LEFT JOIN item_changes AS previous_changes ON previous_changes.id = (
+  SELECT max(id) FROM item_changes WHERE item_id = action_items.item_id AND id < action_items.first_change_id
+)
+LEFT JOIN item_changes AS conflicting_changes ON conflicting_changes.id = (
+  SELECT max(item_changes.id)
+  FROM item_changes
+  LEFT JOIN item_actions ON item_changes.action_id = item_actions.id
+  WHERE item_changes.item_id = action_items.item_id
+  AND item_changes.id > action_items.last_change_id
+  AND (item_changes.user_id != ?2 OR item_actions.id IS NULL OR item_actions.kind = 'system')
+)
+LEFT JOIN users AS conflicting_users ON conflicting_changes.user_id = conflicting_users.id
+ORDER BY action_items.first_change_id DESC
+;
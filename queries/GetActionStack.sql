@@ -0,0 +1,106 @@
+-- The undo stack holds the actions of the user which are not undone yet, newest first.
+-- The redo stack holds the undos of the user which are not redone yet, but only until the user does something new.
+-- Entries of the redo stack show the changes of the action they undid, that is what redoing brings back.
+WITH stack AS (
+  SELECT
+    item_actions.id AS action_id,
+    CASE WHEN ?2 = 'redo' THEN item_actions.reverted_action_id ELSE item_actions.id END AS shown_action_id
+  FROM item_actions
+  WHERE item_actions.user_id = ?1
+  AND NOT EXISTS (SELECT 1 FROM item_actions AS reverting_actions WHERE reverting_actions.reverted_action_id = item_actions.id)
+  AND (
+    (?2 = 'undo' AND item_actions.kind IN ('do', 'redo'))
+    OR (?2 = 'redo' AND item_actions.kind = 'undo' AND item_actions.id > (
+      SELECT COALESCE(max(id), 0) FROM item_actions WHERE user_id = ?1 AND kind = 'do'
+    ))
+  )
+  ORDER BY item_actions.id DESC
+  LIMIT ?3
+),
+
+changes AS (
+  SELECT
+    item_changes.id,
+    item_changes.item_id,
+    item_changes.user_id,
+    item_changes.action_id,
+    item_changes.dimension_id,
+    item_changes.quantity,
+    item_changes.note,
+    item_changes.state,
+    item_changes.recorded_at,
+    lag(item_changes.state, 1, '') OVER item_history AS previous_state,
+    lag(item_changes.quantity, 1, 0) OVER item_history AS previous_quantity,
+    lag(item_changes.dimension_id, 1, item_changes.dimension_id) OVER item_history AS previous_dimension_id
+  FROM item_changes
+  WHERE item_changes.item_id IN (
+    SELECT item_id FROM item_changes WHERE action_id IN (SELECT shown_action_id FROM stack)
+  )
+  WINDOW item_history AS (PARTITION BY item_changes.item_id ORDER BY item_changes.id ASC)
+)
+
+SELECT
+  stack.action_id,
+  changes.id,
+  strftime('%Y-%m-%dT%H:%M:%SZ', changes.recorded_at),
+  users.id,
+  users.name,
+  products.id,
+  products.name_singular,
+  products.name_plural,
+  lists.name,
+  changes.previous_state,
+  changes.state,
+  changes.previous_quantity,
+  changes.quantity,
+  changes.note,
+  json_object(
+    'id', dimensions.id,
+    'name', dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  ),
+  json_object(
+    'id', previous_dimensions.id,
+    'name', previous_dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = previous_dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  )
+FROM stack
+INNER JOIN changes ON changes.action_id = stack.shown_action_id
+INNER JOIN items ON changes.item_id = items.id
+INNER JOIN lists ON items.list_id = lists.id
+INNER JOIN products ON items.product_id = products.id
+INNER JOIN users ON changes.user_id = users.id
+INNER JOIN dimensions ON changes.dimension_id = dimensions.id
+INNER JOIN dimensions AS previous_dimensions ON changes.previous_dimension_id = previous_dimensions.id
+ORDER BY stack.action_id DESC, changes.id ASC
+;
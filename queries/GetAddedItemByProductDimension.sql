@@ -3,6 +3,7 @@ SELECT
       product_name_singular AS name_singular,
       product_name_plural AS name_plural,
       item_quantity AS quantity,
+      item_note AS note,
       product_id,
       json_object(
         'id', dimension_id,
@@ -13,6 +14,7 @@ SELECT
       SELECT
         item_id,
         item_quantity,
+        item_note,
         product_id,
         product_name_singular,
         product_name_plural,
@@ -23,6 +25,7 @@ SELECT
         SELECT
           items.id AS item_id,
           items.quantity AS item_quantity,
+          items.note AS item_note,
           products.id AS product_id,
           products.name_singular AS product_name_singular,
           products.name_plural AS product_name_plural,
@@ -39,8 +42,8 @@ SELECT
         INNER JOIN products ON items.product_id = products.id
         INNER JOIN dimensions ON items.dimension_id = dimensions.id
         INNER JOIN units ON dimensions.id = units.dimension_id
-        WHERE items.product_id = ? AND items.dimension_id = ? AND items.state = 'added'
+        WHERE items.list_id = ? AND items.product_id = ? AND items.dimension_id = ? AND items.state = 'added'
         ORDER BY dimensions.ordering, units.ordering ASC
       )
-      GROUP BY item_id, item_quantity, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
+      GROUP BY item_id, item_quantity, item_note, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
     );
@@ -4,7 +4,7 @@ WITH added_items AS (
     changed_at
   FROM
     items
-  WHERE state = 'added'
+  WHERE state = 'added' AND list_id = ?
   ORDER BY changed_at ASC
   LIMIT 100
 )
@@ -14,6 +14,7 @@ SELECT
   name_singular,
   name_plural,
   quantity,
+  note,
   product_id,
   dimension
 FROM (
@@ -22,6 +23,7 @@ FROM (
     product_name_singular AS name_singular,
     product_name_plural AS name_plural,
     item_quantity AS quantity,
+    item_note AS note,
     product_id,
     json_object(
       'id', dimension_id,
@@ -32,6 +34,7 @@ FROM (
     SELECT
       item_id,
       item_quantity,
+      item_note,
       product_id,
       product_name_singular,
       product_name_plural,
@@ -42,6 +45,7 @@ FROM (
       SELECT
         items.id AS item_id,
         items.quantity AS item_quantity,
+        items.note AS item_note,
         added_items.changed_at AS item_changed_at,
         products.id AS product_id,
         products.name_singular AS product_name_singular,
@@ -62,7 +66,7 @@ FROM (
       INNER JOIN added_items ON items.id = added_items.id
       ORDER BY dimensions.ordering, units.ordering ASC
     )
-    GROUP BY item_id, item_quantity, item_changed_at, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
+    GROUP BY item_id, item_quantity, item_note, item_changed_at, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
     ORDER BY item_changed_at DESC
   )
 )
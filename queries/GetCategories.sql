@@ -1 +1 @@
-SELECT id, name FROM categories ORDER BY ordering ASC LIMIT 10;
+SELECT id, name FROM categories WHERE household_id = ? ORDER BY ordering ASC;
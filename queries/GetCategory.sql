@@ -0,0 +1 @@
+SELECT id, name FROM categories WHERE household_id = ?1 AND id = ?2;
@@ -0,0 +1,11 @@
+SELECT
+      merged.id,
+      merged.list_id,
+      merged.dimension_id,
+      merged.quantity,
+      merged.note
+FROM items AS merged
+WHERE merged.product_id = ?1 AND merged.state = 'added' AND EXISTS (
+  SELECT 1 FROM items AS surviving
+  WHERE surviving.product_id = ?2 AND surviving.list_id = merged.list_id AND surviving.dimension_id = merged.dimension_id AND surviving.state = 'added'
+);
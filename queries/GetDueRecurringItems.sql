@@ -0,0 +1,4 @@
+SELECT id, list_id, user_id, product_id, dimension_id, quantity
+FROM recurring_items
+WHERE next_due_at <= datetime('now')
+ORDER BY next_due_at ASC;
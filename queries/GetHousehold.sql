@@ -0,0 +1 @@
+SELECT id, name FROM households WHERE id = ? LIMIT 1;
@@ -3,7 +3,9 @@ SELECT
       product_name_singular AS name_singular,
       product_name_plural AS name_plural,
       item_quantity AS quantity,
+      item_note AS note,
       item_state AS state,
+      item_list_id AS list_id,
       product_id,
       json_object(
         'id', dimension_id,
@@ -14,7 +16,9 @@ SELECT
       SELECT
         item_id,
         item_quantity,
+        item_note,
         item_state,
+        item_list_id,
         product_id,
         product_name_singular,
         product_name_plural,
@@ -25,7 +29,9 @@ SELECT
         SELECT
           items.id AS item_id,
           items.quantity AS item_quantity,
+          items.note AS item_note,
           items.state AS item_state,
+          items.list_id AS item_list_id,
           products.id AS product_id,
           products.name_singular AS product_name_singular,
           products.name_plural AS product_name_plural,
@@ -42,8 +48,9 @@ SELECT
         INNER JOIN products ON items.product_id = products.id
         INNER JOIN dimensions ON items.dimension_id = dimensions.id
         INNER JOIN units ON dimensions.id = units.dimension_id
-        WHERE items.id = ?
+        INNER JOIN lists ON items.list_id = lists.id
+        WHERE lists.household_id = ? AND items.id = ?
         ORDER BY dimensions.ordering, units.ordering ASC
       )
-      GROUP BY item_id, item_quantity, item_state, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
+      GROUP BY item_id, item_quantity, item_note, item_state, item_list_id, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
     );
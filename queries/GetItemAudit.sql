@@ -0,0 +1,91 @@
+-- The previous change of the same item tells which transition of the state machine a change was.
+-- It has to be looked up before filtering, otherwise the filters would hide it.
+WITH changes AS (
+  SELECT
+    item_changes.id,
+    item_changes.item_id,
+    item_changes.user_id,
+    item_changes.dimension_id,
+    item_changes.quantity,
+    item_changes.note,
+    item_changes.price,
+    item_changes.state,
+    item_changes.recorded_at,
+    lag(item_changes.state, 1, '') OVER item_history AS previous_state,
+    lag(item_changes.quantity, 1, 0) OVER item_history AS previous_quantity,
+    lag(item_changes.dimension_id, 1, item_changes.dimension_id) OVER item_history AS previous_dimension_id
+  FROM item_changes
+  WINDOW item_history AS (PARTITION BY item_changes.item_id ORDER BY item_changes.id ASC)
+)
+
+SELECT
+  changes.id,
+  strftime('%Y-%m-%dT%H:%M:%SZ', changes.recorded_at),
+  users.id,
+  users.name,
+  products.id,
+  products.name_singular,
+  products.name_plural,
+  lists.name,
+  changes.previous_state,
+  changes.state,
+  changes.previous_quantity,
+  changes.quantity,
+  changes.note,
+  COALESCE(changes.price, 0),
+  json_object(
+    'id', dimensions.id,
+    'name', dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  ),
+  json_object(
+    'id', previous_dimensions.id,
+    'name', previous_dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = previous_dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  )
+FROM changes
+INNER JOIN items ON changes.item_id = items.id
+INNER JOIN lists ON items.list_id = lists.id
+INNER JOIN products ON items.product_id = products.id
+INNER JOIN users ON changes.user_id = users.id
+INNER JOIN dimensions ON changes.dimension_id = dimensions.id
+INNER JOIN dimensions AS previous_dimensions ON changes.previous_dimension_id = previous_dimensions.id
+WHERE lists.household_id = ?1
+  AND (?2 = 0 OR users.id = ?2)
+  AND (?3 = 0 OR products.id = ?3)
+  AND (?4 = '' OR changes.state = ?4)
+  AND changes.recorded_at >= ?5
+  AND changes.recorded_at < ?6
+ORDER BY changes.recorded_at DESC, changes.id DESC
+LIMIT ?7
+;
@@ -0,0 +1 @@
+SELECT id, name FROM lists WHERE household_id = ? ORDER BY ordering ASC;
@@ -35,7 +35,7 @@ SELECT
           INNER JOIN dimensions_products ON products.id = dimensions_products.product_id
           INNER JOIN dimensions ON dimensions_products.dimension_id = dimensions.id
           INNER JOIN units ON dimensions.id = units.dimension_id
-          WHERE products.id = ?
+          WHERE products.household_id = ? AND products.id = ?
           ORDER BY dimensions.ordering, units.ordering ASC
         )
         GROUP BY product_id, product_name, dimension_id, dimension_name
@@ -0,0 +1,7 @@
+SELECT
+      id,
+      product_id,
+      name
+FROM product_aliases
+WHERE household_id = ? AND id = ?
+;
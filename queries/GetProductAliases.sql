@@ -0,0 +1,8 @@
+SELECT
+      id,
+      product_id,
+      name
+FROM product_aliases
+WHERE product_id = ?
+ORDER BY name ASC
+;
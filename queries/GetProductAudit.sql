@@ -0,0 +1,35 @@
+WITH changes AS (
+  SELECT
+    product_changes.id,
+    product_changes.product_id,
+    product_changes.user_id,
+    product_changes.name_singular,
+    product_changes.name_plural,
+    product_changes.recorded_at,
+    lag(product_changes.name_singular, 1, '') OVER product_history AS previous_name_singular,
+    lag(product_changes.name_plural, 1, '') OVER product_history AS previous_name_plural
+  FROM product_changes
+  WINDOW product_history AS (PARTITION BY product_changes.product_id ORDER BY product_changes.id ASC)
+)
+
+SELECT
+  changes.id,
+  strftime('%Y-%m-%dT%H:%M:%SZ', changes.recorded_at),
+  users.id,
+  users.name,
+  products.id,
+  changes.name_singular,
+  changes.name_plural,
+  changes.previous_name_singular,
+  changes.previous_name_plural
+FROM changes
+INNER JOIN products ON changes.product_id = products.id
+INNER JOIN users ON changes.user_id = users.id
+WHERE products.household_id = ?1
+  AND (?2 = 0 OR users.id = ?2)
+  AND (?3 = 0 OR products.id = ?3)
+  AND changes.recorded_at >= ?4
+  AND changes.recorded_at < ?5
+ORDER BY changes.recorded_at DESC, changes.id DESC
+LIMIT ?6
+;
@@ -1,8 +1,17 @@
+-- Aliases resolve to the product they belong to.
 SELECT
       id,
       name_singular,
       name_plural
     FROM products
-    WHERE lower(name_singular, 'de_AT') = lower(?) OR lower(name_plural, 'de_AT') = lower(?)
-    LIMIT 1
+    WHERE household_id = ?1 AND (lower(name_singular, 'de_AT') = lower(?2) OR lower(name_plural, 'de_AT') = lower(?2))
+UNION ALL
+SELECT
+      products.id,
+      products.name_singular,
+      products.name_plural
+    FROM product_aliases
+    INNER JOIN products ON product_aliases.product_id = products.id
+    WHERE product_aliases.household_id = ?1 AND lower(product_aliases.name, 'de_AT') = lower(?2)
+LIMIT 1
 ;
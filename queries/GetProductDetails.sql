@@ -0,0 +1,8 @@
+SELECT
+      products.id,
+      products.name_singular,
+      products.name_plural,
+      (SELECT json_group_array(category_id) FROM categories_products WHERE product_id = products.id) AS category_ids,
+      (SELECT json_group_array(dimension_id) FROM dimensions_products WHERE product_id = products.id) AS dimension_ids
+FROM products
+WHERE products.household_id = ?1 AND products.id = ?2;
@@ -3,6 +3,7 @@ SELECT
       name_singular,
       name_plural
 FROM products
+WHERE household_id = ?
 ORDER BY name_plural ASC
 LIMIT 1000
 ;
@@ -0,0 +1,13 @@
+-- Same filter as GetPurchases, days whose gathered transitions were all undone have no purchases.
+SELECT DISTINCT date(item_changes.recorded_at, 'localtime') AS purchase_date
+FROM item_changes
+INNER JOIN items ON item_changes.item_id = items.id
+INNER JOIN lists ON items.list_id = lists.id
+WHERE lists.household_id = ? AND item_changes.state = 'gathered'
+  AND NOT EXISTS (
+    SELECT 1
+    FROM item_changes AS later_changes
+    WHERE later_changes.item_id = item_changes.item_id AND later_changes.id > item_changes.id
+  )
+ORDER BY purchase_date DESC
+LIMIT 100;
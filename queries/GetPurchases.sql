@@ -0,0 +1,70 @@
+-- Gathered transitions which were not undone afterwards, that is the last change of their item.
+WITH purchases AS (
+  SELECT
+    item_changes.id,
+    item_changes.item_id,
+    item_changes.user_id,
+    item_changes.dimension_id,
+    item_changes.quantity,
+    item_changes.price,
+    item_changes.recorded_at
+  FROM item_changes
+  INNER JOIN items ON item_changes.item_id = items.id
+  INNER JOIN lists ON items.list_id = lists.id
+  WHERE lists.household_id = ?1
+    AND item_changes.state = 'gathered'
+    AND item_changes.recorded_at >= ?2
+    AND item_changes.recorded_at < ?3
+    AND NOT EXISTS (
+      SELECT 1
+      FROM item_changes AS later_changes
+      WHERE later_changes.item_id = item_changes.item_id AND later_changes.id > item_changes.id
+    )
+)
+
+SELECT
+  purchases.item_id,
+  products.name_singular,
+  products.name_plural,
+  purchases.quantity,
+  products.id,
+  users.id,
+  users.name,
+  strftime('%Y-%m-%dT%H:%M:%SZ', purchases.recorded_at),
+  purchases.price,
+  -- Products in several categories count towards the one visited first.
+  COALESCE((
+    SELECT categories.name
+    FROM categories_products
+    INNER JOIN categories ON categories_products.category_id = categories.id
+    WHERE categories_products.product_id = products.id
+    ORDER BY categories.ordering ASC
+    LIMIT 1
+  ), ''),
+  json_object(
+    'id', dimensions.id,
+    'name', dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  )
+FROM purchases
+INNER JOIN items ON purchases.item_id = items.id
+INNER JOIN products ON items.product_id = products.id
+INNER JOIN dimensions ON purchases.dimension_id = dimensions.id
+INNER JOIN users ON purchases.user_id = users.id
+ORDER BY purchases.recorded_at ASC, purchases.id ASC
+;
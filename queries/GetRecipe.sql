@@ -0,0 +1 @@
+SELECT id, name, servings FROM recipes WHERE household_id = ?1 AND id = ?2;
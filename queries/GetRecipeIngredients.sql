@@ -0,0 +1,33 @@
+SELECT
+  recipe_ingredients.id,
+  recipe_ingredients.recipe_id,
+  products.id,
+  products.name_singular,
+  products.name_plural,
+  recipe_ingredients.quantity,
+  json_object(
+    'id', dimensions.id,
+    'name', dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  )
+FROM recipe_ingredients
+INNER JOIN products ON recipe_ingredients.product_id = products.id
+INNER JOIN dimensions ON recipe_ingredients.dimension_id = dimensions.id
+WHERE recipe_ingredients.recipe_id = ?
+ORDER BY products.name_plural ASC, recipe_ingredients.id ASC
+;
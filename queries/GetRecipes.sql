@@ -0,0 +1 @@
+SELECT id, name, servings FROM recipes WHERE household_id = ? ORDER BY name ASC;
@@ -0,0 +1,36 @@
+SELECT
+  recurring_items.id,
+  recurring_items.list_id,
+  recurring_items.user_id,
+  products.name_singular,
+  products.name_plural,
+  recurring_items.quantity,
+  products.id,
+  recurring_items.interval_days,
+  strftime('%Y-%m-%dT%H:%M:%SZ', recurring_items.next_due_at),
+  json_object(
+    'id', dimensions.id,
+    'name', dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  )
+FROM recurring_items
+INNER JOIN products ON recurring_items.product_id = products.id
+INNER JOIN dimensions ON recurring_items.dimension_id = dimensions.id
+WHERE recurring_items.list_id = ?
+ORDER BY recurring_items.next_due_at ASC, products.name_plural ASC
+;
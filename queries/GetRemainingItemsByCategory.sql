@@ -1,8 +1,9 @@
 SELECT
-      DISTINCT id,
+      id,
       name_singular,
       name_plural,
       quantity,
+      note,
       complete_items.product_id AS product_id,
       dimension
     FROM (
@@ -11,6 +12,7 @@ SELECT
         product_name_singular AS name_singular,
         product_name_plural AS name_plural,
         item_quantity AS quantity,
+        item_note AS note,
         product_id,
         json_object(
           'id', dimension_id,
@@ -21,6 +23,7 @@ SELECT
         SELECT
           item_id,
           item_quantity,
+          item_note,
           product_id,
           product_name_singular,
           product_name_plural,
@@ -31,6 +34,7 @@ SELECT
           SELECT
             items.id AS item_id,
             items.quantity AS item_quantity,
+            items.note AS item_note,
             products.id AS product_id,
             products.name_singular AS product_name_singular,
             products.name_plural AS product_name_plural,
@@ -47,13 +51,19 @@ SELECT
           INNER JOIN products ON items.product_id = products.id
           INNER JOIN dimensions ON items.dimension_id = dimensions.id
           INNER JOIN units ON dimensions.id = units.dimension_id
-          WHERE items.state = 'added'
+          WHERE items.list_id = ?1 AND items.state = 'added'
           ORDER BY dimensions.ordering, units.ordering ASC
         )
-        GROUP BY item_id, item_quantity, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
+        GROUP BY item_id, item_quantity, item_note, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
       )
     ) complete_items
-    INNER JOIN categories_products ON complete_items.product_id = categories_products.product_id
-    ORDER BY categories_products.category_id <> ?, name_plural ASC
+    ORDER BY
+      (
+        SELECT MIN(categories.ordering)
+        FROM categories_products
+        INNER JOIN categories ON categories_products.category_id = categories.id
+        WHERE categories_products.product_id = complete_items.product_id
+      ),
+      name_plural ASC
     LIMIT 100
     ;
@@ -3,7 +3,7 @@ WITH removed_items AS (
     id,
     changed_at
   FROM items
-  WHERE state = 'removed' AND changed_at >= datetime('now', '-6 hours')
+  WHERE list_id = ? AND state = 'removed' AND changed_at >= datetime('now', '-6 hours')
   ORDER BY changed_at DESC
   LIMIT 20
 )
@@ -13,6 +13,7 @@ SELECT
   name_singular,
   name_plural,
   quantity,
+  note,
   product_id,
   dimension
 FROM (
@@ -21,6 +22,7 @@ FROM (
     product_name_singular AS name_singular,
     product_name_plural AS name_plural,
     item_quantity AS quantity,
+    item_note AS note,
     product_id,
     json_object(
       'id', dimension_id,
@@ -31,6 +33,7 @@ FROM (
     SELECT
       item_id,
       item_quantity,
+      item_note,
       product_id,
       product_name_singular,
       product_name_plural,
@@ -41,6 +44,7 @@ FROM (
       SELECT
         items.id AS item_id,
         items.quantity AS item_quantity,
+        items.note AS item_note,
         removed_items.changed_at AS item_changed_at,
         products.id AS product_id,
         products.name_singular AS product_name_singular,
@@ -61,7 +65,7 @@ FROM (
       INNER JOIN removed_items ON items.id = removed_items.id
       ORDER BY dimensions.ordering, units.ordering ASC
     )
-    GROUP BY item_id, item_quantity, item_changed_at, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
+    GROUP BY item_id, item_quantity, item_note, item_changed_at, product_id, product_name_singular, product_name_plural, dimension_id, dimension_name
     ORDER BY item_changed_at DESC
   )
 )
@@ -0,0 +1,33 @@
+SELECT
+  stock_levels.id,
+  products.id,
+  products.name_singular,
+  products.name_plural,
+  stock_levels.quantity,
+  COALESCE(stock_levels.minimum_quantity, 0),
+  COALESCE(stock_levels.list_id, 0),
+  json_object(
+    'id', dimensions.id,
+    'name', dimensions.name,
+    'units', (
+      SELECT json_group_array(json(unit))
+      FROM (
+        SELECT
+          json_object(
+            'id', units.id,
+            'name_singular', units.name_singular,
+            'name_plural', units.name_plural,
+            'conversion_to_base', units.conversion_to_base,
+            'conversion_from_base', units.conversion_from_base
+          ) AS unit
+        FROM units
+        WHERE units.dimension_id = dimensions.id
+        ORDER BY units.ordering ASC
+      )
+    )
+  )
+FROM stock_levels
+INNER JOIN products ON stock_levels.product_id = products.id
+INNER JOIN dimensions ON stock_levels.dimension_id = dimensions.id
+WHERE products.household_id = ?1 AND stock_levels.id = ?2
+;
@@ -0,0 +1 @@
+SELECT id, name FROM stores WHERE household_id = ?1 AND id = ?2;
@@ -0,0 +1,5 @@
+SELECT categories.id, categories.name
+FROM categories
+LEFT JOIN stores_categories ON stores_categories.category_id = categories.id AND stores_categories.store_id = ?2
+WHERE categories.household_id = ?1
+ORDER BY stores_categories.ordering IS NULL, stores_categories.ordering ASC, categories.ordering ASC;
@@ -0,0 +1 @@
+SELECT id, name FROM stores WHERE household_id = ? ORDER BY name ASC;
@@ -0,0 +1,8 @@
+SELECT
+      id,
+      name_singular,
+      name_plural
+FROM products
+WHERE NOT EXISTS (SELECT 1 FROM product_trigrams WHERE product_trigrams.product_id = products.id)
+ORDER BY id ASC
+;
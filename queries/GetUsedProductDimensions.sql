@@ -0,0 +1,7 @@
+SELECT dimension_id FROM items WHERE product_id = ?1 AND state = 'added'
+UNION
+SELECT dimension_id FROM recurring_items WHERE product_id = ?1
+UNION
+SELECT dimension_id FROM recipe_ingredients WHERE product_id = ?1
+UNION
+SELECT dimension_id FROM stock_levels WHERE product_id = ?1 AND minimum_quantity IS NOT NULL;
@@ -0,0 +1 @@
+SELECT id, household_id, name FROM users WHERE email = ? LIMIT 1;
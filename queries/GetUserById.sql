@@ -1 +1 @@
-SELECT id, name FROM users WHERE id = ? LIMIT 1;
+SELECT id, household_id, name FROM users WHERE household_id = ? AND id = ? LIMIT 1;
@@ -1 +1 @@
-SELECT id, name FROM users ORDER BY name ASC LIMIT 10;
+SELECT id, household_id, name FROM users WHERE household_id = ? ORDER BY name ASC LIMIT 10;
@@ -0,0 +1 @@
+INSERT INTO failed_logins (email, attempted_at) VALUES (?, datetime('now'));
@@ -0,0 +1 @@
+INSERT INTO households (name) VALUES (?);
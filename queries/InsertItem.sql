@@ -1 +1 @@
-INSERT INTO items (product_id, dimension_id, quantity, state, changed_at) VALUES (?, ?, ?, 'added', datetime('now'));
+INSERT INTO items (list_id, product_id, dimension_id, quantity, note, state, changed_at) VALUES (?, ?, ?, ?, ?, 'added', datetime('now'));
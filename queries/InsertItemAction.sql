@@ -0,0 +1 @@
+INSERT INTO item_actions (user_id, kind, reverted_action_id, recorded_at) VALUES (?1, ?2, NULLIF(?3, 0), datetime('now'));
@@ -1,8 +1,10 @@
 INSERT INTO item_changes (
   item_id,
   user_id,
+  action_id,
   dimension_id,
   quantity,
+  note,
   state,
   recorded_at
 ) VALUES (
@@ -11,5 +13,7 @@ INSERT INTO item_changes (
   ?,
   ?,
   ?,
+  ?,
+  ?,
   datetime('now')
 );
@@ -0,0 +1 @@
+INSERT INTO lists (household_id, name, ordering) VALUES (?1, ?2, (SELECT COALESCE(MAX(ordering), 0) + 1 FROM lists WHERE household_id = ?1));
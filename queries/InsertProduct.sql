@@ -1 +1 @@
-INSERT INTO products (name_singular, name_plural) VALUES (?, ?);
+INSERT INTO products (household_id, name_singular, name_plural) VALUES (?, ?, ?);
@@ -0,0 +1 @@
+INSERT INTO product_aliases (household_id, product_id, name) VALUES (?, ?, ?);
@@ -0,0 +1 @@
+INSERT INTO product_trigrams (product_id, trigram) VALUES (?, ?) ON CONFLICT DO NOTHING;
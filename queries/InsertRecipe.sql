@@ -0,0 +1 @@
+INSERT INTO recipes (household_id, name, servings) VALUES (?, ?, ?);
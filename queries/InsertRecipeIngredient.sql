@@ -0,0 +1 @@
+INSERT INTO recipe_ingredients (recipe_id, product_id, dimension_id, quantity) VALUES (?, ?, ?, ?);
@@ -0,0 +1,17 @@
+INSERT INTO recurring_items (
+  list_id,
+  product_id,
+  dimension_id,
+  user_id,
+  quantity,
+  interval_days,
+  next_due_at
+) VALUES (
+  ?,
+  ?,
+  ?,
+  ?,
+  ?,
+  ?,
+  ?
+);
@@ -0,0 +1 @@
+INSERT INTO stores (household_id, name) VALUES (?, ?);
@@ -0,0 +1 @@
+INSERT INTO stores_categories (store_id, category_id, ordering) VALUES (?, ?, ?);
@@ -0,0 +1 @@
+INSERT INTO users (household_id, name, email) VALUES (?, ?, ?);
@@ -0,0 +1 @@
+UPDATE items SET product_id = ?2 WHERE product_id = ?1;
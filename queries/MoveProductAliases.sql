@@ -0,0 +1 @@
+UPDATE product_aliases SET product_id = ?2 WHERE product_id = ?1;
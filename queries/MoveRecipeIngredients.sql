@@ -0,0 +1 @@
+UPDATE recipe_ingredients SET product_id = ?2 WHERE product_id = ?1;
@@ -0,0 +1 @@
+UPDATE recurring_items SET product_id = ?2 WHERE product_id = ?1;
@@ -0,0 +1,3 @@
+INSERT INTO stock_levels (product_id, dimension_id, quantity, minimum_quantity, list_id)
+SELECT ?2, dimension_id, quantity, minimum_quantity, list_id FROM stock_levels WHERE product_id = ?1 AND true
+ON CONFLICT(product_id, dimension_id) DO UPDATE SET quantity = min(stock_levels.quantity + excluded.quantity, 1000000);
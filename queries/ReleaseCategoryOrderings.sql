@@ -0,0 +1 @@
+UPDATE categories SET ordering = -ordering WHERE household_id = ?;
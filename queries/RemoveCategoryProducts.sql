@@ -0,0 +1 @@
+DELETE FROM categories_products WHERE category_id = ?;
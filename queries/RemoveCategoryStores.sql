@@ -0,0 +1 @@
+DELETE FROM stores_categories WHERE category_id = ?;
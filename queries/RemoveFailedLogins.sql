@@ -0,0 +1 @@
+DELETE FROM failed_logins WHERE email = ?;
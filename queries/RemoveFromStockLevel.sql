@@ -0,0 +1 @@
+UPDATE stock_levels SET quantity = max(quantity - ?3, 0) WHERE product_id = ?1 AND dimension_id = ?2;
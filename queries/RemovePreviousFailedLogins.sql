@@ -0,0 +1 @@
+DELETE FROM failed_logins WHERE attempted_at < datetime('now', '-1 day');
@@ -0,0 +1 @@
+DELETE FROM products WHERE household_id = ?1 AND id = ?2;
@@ -0,0 +1 @@
+DELETE FROM product_aliases WHERE household_id = ? AND id = ?;
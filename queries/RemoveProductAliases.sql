@@ -0,0 +1 @@
+DELETE FROM product_aliases WHERE product_id = ?;
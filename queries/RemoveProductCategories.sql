@@ -0,0 +1 @@
+DELETE FROM categories_products WHERE product_id = ?;
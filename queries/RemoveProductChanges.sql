@@ -0,0 +1 @@
+DELETE FROM product_changes WHERE product_id = ?;
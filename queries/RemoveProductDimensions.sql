@@ -0,0 +1 @@
+DELETE FROM dimensions_products WHERE product_id = ?;
@@ -0,0 +1 @@
+DELETE FROM product_trigrams WHERE product_id = ?;
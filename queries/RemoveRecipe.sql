@@ -0,0 +1 @@
+DELETE FROM recipes WHERE household_id = ?1 AND id = ?2;
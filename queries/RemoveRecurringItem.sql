@@ -0,0 +1,2 @@
+DELETE FROM recurring_items
+WHERE id = ?1 AND list_id IN (SELECT id FROM lists WHERE household_id = ?2);
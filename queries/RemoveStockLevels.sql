@@ -0,0 +1 @@
+DELETE FROM stock_levels WHERE product_id = ?;
@@ -0,0 +1 @@
+DELETE FROM stores WHERE household_id = ?1 AND id = ?2;
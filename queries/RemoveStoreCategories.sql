@@ -0,0 +1 @@
+DELETE FROM stores_categories WHERE store_id = ?;
@@ -0,0 +1 @@
+UPDATE items SET state = ?2, dimension_id = ?3, quantity = ?4, note = ?5, changed_at = datetime('now') WHERE id = ?1;
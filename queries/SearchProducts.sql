@@ -0,0 +1,32 @@
+-- Products sharing at least 40% of the trigrams of the query, so a typo or two still matches.
+-- Better matches come first, products with equally good matches are ordered by how often they were put on a list.
+WITH query AS (
+  SELECT value AS trigram FROM json_each(?2)
+),
+matches AS (
+  SELECT
+    product_trigrams.product_id,
+    ROUND(CAST(COUNT(*) AS REAL) / (SELECT COUNT(*) FROM query), 1) AS similarity
+  FROM product_trigrams
+  INNER JOIN query ON product_trigrams.trigram = query.trigram
+  GROUP BY product_trigrams.product_id
+)
+
+SELECT
+      products.id,
+      products.name_singular,
+      products.name_plural
+FROM matches
+INNER JOIN products ON matches.product_id = products.id
+WHERE products.household_id = ?1 AND matches.similarity >= 0.4
+ORDER BY
+  matches.similarity DESC,
+  (
+    SELECT COUNT(*)
+    FROM item_changes
+    INNER JOIN items ON item_changes.item_id = items.id
+    WHERE items.product_id = products.id AND item_changes.state = 'added'
+  ) DESC,
+  products.name_plural ASC
+LIMIT ?3
+;
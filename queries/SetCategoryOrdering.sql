@@ -0,0 +1 @@
+UPDATE categories SET ordering = ?3 WHERE household_id = ?1 AND id = ?2;
@@ -0,0 +1 @@
+UPDATE items SET note = ?, changed_at = datetime('now') WHERE id = ?;
@@ -0,0 +1,3 @@
+UPDATE item_changes
+SET price = ?2
+WHERE id = (SELECT MAX(id) FROM item_changes WHERE item_id = ?1);
@@ -0,0 +1 @@
+UPDATE users SET password_hash = ? WHERE id = ?;
@@ -0,0 +1 @@
+UPDATE stock_levels SET minimum_quantity = NULLIF(?2, 0), list_id = CASE WHEN ?2 = 0 THEN NULL ELSE ?3 END WHERE id = ?1;
@@ -0,0 +1 @@
+UPDATE categories SET name = ?3 WHERE household_id = ?1 AND id = ?2;
@@ -0,0 +1 @@
+UPDATE products SET name_singular = ?3, name_plural = ?4 WHERE household_id = ?1 AND id = ?2;
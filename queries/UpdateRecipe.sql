@@ -0,0 +1 @@
+UPDATE recipes SET name = ?3, servings = ?4 WHERE household_id = ?1 AND id = ?2;
@@ -0,0 +1 @@
+UPDATE stores SET name = ?3 WHERE household_id = ?1 AND id = ?2;
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"embed"
 	"strings"
+	"time"
 
 	"stravid.com/besserliste/types"
 )
@@ -41,12 +42,12 @@ func Build(db *sql.DB) Queries {
 	}
 }
 
-func (stmt *Queries) GetCategories(tx *sql.Tx) ([]types.Category, error) {
+func (stmt *Queries) GetCategories(tx *sql.Tx, householdId int) ([]types.Category, error) {
 	if _, ok := stmt.statements["GetCategories"]; !ok {
 		return nil, errors.New("Unknown query `GetCategories`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetCategories"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetCategories"]).Query(householdId)
 	if err != nil {
 		return nil, err
 	}
@@ -69,14 +70,14 @@ func (stmt *Queries) GetCategories(tx *sql.Tx) ([]types.Category, error) {
 	return categories, nil
 }
 
-func (stmt *Queries) GetUserById(tx *sql.Tx, id int) (*types.User, error) {
+func (stmt *Queries) GetUserById(tx *sql.Tx, householdId int, id int) (*types.User, error) {
 	if _, ok := stmt.statements["GetUserById"]; !ok {
 		return nil, errors.New("Unknown query `GetUserById`")
 	}
 
-	row := tx.Stmt(stmt.statements["GetUserById"]).QueryRow(id)
+	row := tx.Stmt(stmt.statements["GetUserById"]).QueryRow(householdId, id)
 	user := types.User{}
-	err := row.Scan(&user.Id, &user.Name)
+	err := row.Scan(&user.Id, &user.HouseholdId, &user.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -84,12 +85,70 @@ func (stmt *Queries) GetUserById(tx *sql.Tx, id int) (*types.User, error) {
 	return &user, nil
 }
 
-func (stmt *Queries) GetUsers(tx *sql.Tx) ([]types.User, error) {
+func (stmt *Queries) GetLists(tx *sql.Tx, householdId int) ([]types.List, error) {
+	if _, ok := stmt.statements["GetLists"]; !ok {
+		return nil, errors.New("Unknown query `GetLists`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetLists"]).Query(householdId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []types.List{}
+	for rows.Next() {
+		list := types.List{}
+		err = rows.Scan(&list.Id, &list.Name)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (stmt *Queries) GetUserByEmail(tx *sql.Tx, email string) (*types.User, error) {
+	if _, ok := stmt.statements["GetUserByEmail"]; !ok {
+		return nil, errors.New("Unknown query `GetUserByEmail`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetUserByEmail"]).QueryRow(email)
+	user := types.User{}
+	err := row.Scan(&user.Id, &user.HouseholdId, &user.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (stmt *Queries) GetHousehold(tx *sql.Tx, id int) (*types.Household, error) {
+	if _, ok := stmt.statements["GetHousehold"]; !ok {
+		return nil, errors.New("Unknown query `GetHousehold`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetHousehold"]).QueryRow(id)
+	household := types.Household{}
+	err := row.Scan(&household.Id, &household.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &household, nil
+}
+
+func (stmt *Queries) GetUsers(tx *sql.Tx, householdId int) ([]types.User, error) {
 	if _, ok := stmt.statements["GetUsers"]; !ok {
 		return nil, errors.New("Unknown query `GetUsers`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetUsers"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetUsers"]).Query(householdId)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +157,7 @@ func (stmt *Queries) GetUsers(tx *sql.Tx) ([]types.User, error) {
 	users := []types.User{}
 	for rows.Next() {
 		user := types.User{}
-		err = rows.Scan(&user.Id, &user.Name)
+		err = rows.Scan(&user.Id, &user.HouseholdId, &user.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -112,12 +171,12 @@ func (stmt *Queries) GetUsers(tx *sql.Tx) ([]types.User, error) {
 	return users, nil
 }
 
-func (stmt *Queries) GetProducts(tx *sql.Tx) ([]types.Product, error) {
+func (stmt *Queries) GetProducts(tx *sql.Tx, householdId int) ([]types.Product, error) {
 	if _, ok := stmt.statements["GetProducts"]; !ok {
 		return nil, errors.New("Unknown query `GetProducts`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetProducts"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetProducts"]).Query(householdId)
 	if err != nil {
 		return nil, err
 	}
@@ -176,12 +235,12 @@ func (stmt *Queries) GetDimensions(tx *sql.Tx) ([]types.Dimension, error) {
 	return dimensions, nil
 }
 
-func (stmt *Queries) GetProduct(tx *sql.Tx, id int) (*types.SelectedProduct, error) {
+func (stmt *Queries) GetProduct(tx *sql.Tx, householdId int, id int) (*types.SelectedProduct, error) {
 	if _, ok := stmt.statements["GetProduct"]; !ok {
 		return nil, errors.New("Unknown query `GetProduct`")
 	}
 
-	row := tx.Stmt(stmt.statements["GetProduct"]).QueryRow(id)
+	row := tx.Stmt(stmt.statements["GetProduct"]).QueryRow(householdId, id)
 	product := types.SelectedProduct{}
 	var dimensionsJson string
 	err := row.Scan(&product.Id, &product.Name, &dimensionsJson)
@@ -197,15 +256,15 @@ func (stmt *Queries) GetProduct(tx *sql.Tx, id int) (*types.SelectedProduct, err
 	return &product, nil
 }
 
-func (stmt *Queries) GetAddedItemByProductDimension(tx *sql.Tx, productId int, dimensionId int) (*types.AddedItem, error) {
+func (stmt *Queries) GetAddedItemByProductDimension(tx *sql.Tx, listId int, productId int, dimensionId int) (*types.AddedItem, error) {
 	if _, ok := stmt.statements["GetAddedItemByProductDimension"]; !ok {
 		return nil, errors.New("Unknown query `GetAddedItemByProductDimension`")
 	}
 
-	row := tx.Stmt(stmt.statements["GetAddedItemByProductDimension"]).QueryRow(productId, dimensionId)
+	row := tx.Stmt(stmt.statements["GetAddedItemByProductDimension"]).QueryRow(listId, productId, dimensionId)
 	i := types.AddedItem{}
 	var dimensionJson string
-	err := row.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &dimensionJson)
+	err := row.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
 	if err != nil {
 		return nil, err
 	}
@@ -218,12 +277,12 @@ func (stmt *Queries) GetAddedItemByProductDimension(tx *sql.Tx, productId int, d
 	return &i, nil
 }
 
-func (stmt *Queries) GetAddedItems(tx *sql.Tx) ([]types.AddedItem, error) {
+func (stmt *Queries) GetAddedItems(tx *sql.Tx, listId int) ([]types.AddedItem, error) {
 	if _, ok := stmt.statements["GetAddedItems"]; !ok {
 		return nil, errors.New("Unknown query `GetAddedItems`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetAddedItems"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetAddedItems"]).Query(listId)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +293,7 @@ func (stmt *Queries) GetAddedItems(tx *sql.Tx) ([]types.AddedItem, error) {
 		i := types.AddedItem{}
 		var dimensionJson string
 
-		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &dimensionJson)
+		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
 		if err != nil {
 			return nil, err
 		}
@@ -254,12 +313,12 @@ func (stmt *Queries) GetAddedItems(tx *sql.Tx) ([]types.AddedItem, error) {
 	return items, nil
 }
 
-func (stmt *Queries) GetRemainingItemsByAlphabet(tx *sql.Tx) ([]types.AddedItem, error) {
+func (stmt *Queries) GetRemainingItemsByAlphabet(tx *sql.Tx, listId int) ([]types.AddedItem, error) {
 	if _, ok := stmt.statements["GetRemainingItemsByAlphabet"]; !ok {
 		return nil, errors.New("Unknown query `GetRemainingItemsByAlphabet`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetRemainingItemsByAlphabet"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetRemainingItemsByAlphabet"]).Query(listId)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +329,7 @@ func (stmt *Queries) GetRemainingItemsByAlphabet(tx *sql.Tx) ([]types.AddedItem,
 		i := types.AddedItem{}
 		var dimensionJson string
 
-		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &dimensionJson)
+		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
 		if err != nil {
 			return nil, err
 		}
@@ -290,12 +349,48 @@ func (stmt *Queries) GetRemainingItemsByAlphabet(tx *sql.Tx) ([]types.AddedItem,
 	return items, nil
 }
 
-func (stmt *Queries) GetRemainingItemsByCategory(tx *sql.Tx, id int) ([]types.AddedItem, error) {
+func (stmt *Queries) GetRemainingItemsByCategory(tx *sql.Tx, listId int) ([]types.AddedItem, error) {
 	if _, ok := stmt.statements["GetRemainingItemsByCategory"]; !ok {
 		return nil, errors.New("Unknown query `GetRemainingItemsByCategory`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetRemainingItemsByCategory"]).Query(id)
+	rows, err := tx.Stmt(stmt.statements["GetRemainingItemsByCategory"]).Query(listId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []types.AddedItem{}
+	for rows.Next() {
+		i := types.AddedItem{}
+		var dimensionJson string
+
+		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &i.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (stmt *Queries) GetRemainingItemsByStore(tx *sql.Tx, listId int, storeId int) ([]types.AddedItem, error) {
+	if _, ok := stmt.statements["GetRemainingItemsByStore"]; !ok {
+		return nil, errors.New("Unknown query `GetRemainingItemsByStore`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetRemainingItemsByStore"]).Query(listId, storeId)
 	if err != nil {
 		return nil, err
 	}
@@ -306,7 +401,7 @@ func (stmt *Queries) GetRemainingItemsByCategory(tx *sql.Tx, id int) ([]types.Ad
 		i := types.AddedItem{}
 		var dimensionJson string
 
-		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &dimensionJson)
+		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
 		if err != nil {
 			return nil, err
 		}
@@ -326,15 +421,15 @@ func (stmt *Queries) GetRemainingItemsByCategory(tx *sql.Tx, id int) ([]types.Ad
 	return items, nil
 }
 
-func (stmt *Queries) GetItem(tx *sql.Tx, itemId int) (*types.SelectedItem, error) {
+func (stmt *Queries) GetItem(tx *sql.Tx, householdId int, itemId int) (*types.SelectedItem, error) {
 	if _, ok := stmt.statements["GetItem"]; !ok {
 		return nil, errors.New("Unknown query `GetItem`")
 	}
 
-	row := tx.Stmt(stmt.statements["GetItem"]).QueryRow(itemId)
+	row := tx.Stmt(stmt.statements["GetItem"]).QueryRow(householdId, itemId)
 	i := types.SelectedItem{}
 	var dimensionJson string
-	err := row.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.State, &i.ProductId, &dimensionJson)
+	err := row.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.State, &i.ListId, &i.ProductId, &dimensionJson)
 	if err != nil {
 		return nil, err
 	}
@@ -347,12 +442,12 @@ func (stmt *Queries) GetItem(tx *sql.Tx, itemId int) (*types.SelectedItem, error
 	return &i, nil
 }
 
-func (stmt *Queries) GetGatheredItems(tx *sql.Tx) ([]types.AddedItem, error) {
+func (stmt *Queries) GetGatheredItems(tx *sql.Tx, listId int) ([]types.AddedItem, error) {
 	if _, ok := stmt.statements["GetGatheredItems"]; !ok {
 		return nil, errors.New("Unknown query `GetGatheredItems`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetGatheredItems"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetGatheredItems"]).Query(listId)
 	if err != nil {
 		return nil, err
 	}
@@ -363,7 +458,7 @@ func (stmt *Queries) GetGatheredItems(tx *sql.Tx) ([]types.AddedItem, error) {
 		i := types.AddedItem{}
 		var dimensionJson string
 
-		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &dimensionJson)
+		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
 		if err != nil {
 			return nil, err
 		}
@@ -383,12 +478,12 @@ func (stmt *Queries) GetGatheredItems(tx *sql.Tx) ([]types.AddedItem, error) {
 	return items, nil
 }
 
-func (stmt *Queries) GetRemovedItems(tx *sql.Tx) ([]types.AddedItem, error) {
+func (stmt *Queries) GetRemovedItems(tx *sql.Tx, listId int) ([]types.AddedItem, error) {
 	if _, ok := stmt.statements["GetRemovedItems"]; !ok {
 		return nil, errors.New("Unknown query `GetRemovedItems`")
 	}
 
-	rows, err := tx.Stmt(stmt.statements["GetRemovedItems"]).Query()
+	rows, err := tx.Stmt(stmt.statements["GetRemovedItems"]).Query(listId)
 	if err != nil {
 		return nil, err
 	}
@@ -399,7 +494,7 @@ func (stmt *Queries) GetRemovedItems(tx *sql.Tx) ([]types.AddedItem, error) {
 		i := types.AddedItem{}
 		var dimensionJson string
 
-		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &dimensionJson)
+		err := rows.Scan(&i.Id, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.Note, &i.ProductId, &dimensionJson)
 		if err != nil {
 			return nil, err
 		}
@@ -419,12 +514,12 @@ func (stmt *Queries) GetRemovedItems(tx *sql.Tx) ([]types.AddedItem, error) {
 	return items, nil
 }
 
-func (stmt *Queries) GetProductByName(tx *sql.Tx, name string) (*types.Product, error) {
+func (stmt *Queries) GetProductByName(tx *sql.Tx, householdId int, name string) (*types.Product, error) {
 	if _, ok := stmt.statements["GetProductByName"]; !ok {
 		return nil, errors.New("Unknown query `GetProductByName`")
 	}
 
-	row := tx.Stmt(stmt.statements["GetProductByName"]).QueryRow(name, name)
+	row := tx.Stmt(stmt.statements["GetProductByName"]).QueryRow(householdId, name)
 	p := types.Product{}
 	err := row.Scan(&p.Id, &p.NameSingular, &p.NamePlural)
 	if err != nil {
@@ -443,12 +538,12 @@ func (stmt *Queries) SetItemState(tx *sql.Tx, itemId int, state string) (error)
 	return err
 }
 
-func (stmt *Queries) InsertItemChange(tx *sql.Tx, itemId int64, userId int, dimensionId int, quantity int64, state string) (error) {
+func (stmt *Queries) InsertItemChange(tx *sql.Tx, itemId int64, userId int, actionId int64, dimensionId int, quantity int64, note string, state string) (error) {
 	if _, ok := stmt.statements["InsertItemChange"]; !ok {
 		return errors.New("Unknown query `InsertItemChange`")
 	}
 
-	_, err := tx.Stmt(stmt.statements["InsertItemChange"]).Exec(itemId, userId, dimensionId, quantity, state)
+	_, err := tx.Stmt(stmt.statements["InsertItemChange"]).Exec(itemId, userId, actionId, dimensionId, quantity, note, state)
 	return err
 }
 
@@ -479,12 +574,12 @@ func (stmt *Queries) SetItemQuantityForDifferentDimension(tx *sql.Tx, itemId int
 	return err
 }
 
-func (stmt *Queries) InsertProduct(tx *sql.Tx, nameSingular string, namePlural string) (sql.Result, error) {
+func (stmt *Queries) InsertProduct(tx *sql.Tx, householdId int, nameSingular string, namePlural string) (sql.Result, error) {
 	if _, ok := stmt.statements["InsertProduct"]; !ok {
 		return nil, errors.New("Unknown query `InsertProduct`")
 	}
 
-	return tx.Stmt(stmt.statements["InsertProduct"]).Exec(nameSingular, namePlural)
+	return tx.Stmt(stmt.statements["InsertProduct"]).Exec(householdId, nameSingular, namePlural)
 }
 
 func (stmt *Queries) InsertProductDimension(tx *sql.Tx, productId int64, dimensionId string) (sql.Result, error) {
@@ -511,12 +606,12 @@ func (stmt *Queries) InsertProductChange(tx *sql.Tx, productId int64, userId int
 	return tx.Stmt(stmt.statements["InsertProductChange"]).Exec(productId, userId, nameSingular, namePlural)
 }
 
-func (stmt *Queries) InsertItem(tx *sql.Tx, productId int, dimensionId int, quantity int64) (sql.Result, error) {
+func (stmt *Queries) InsertItem(tx *sql.Tx, listId int, productId int, dimensionId int, quantity int64, note string) (sql.Result, error) {
 	if _, ok := stmt.statements["InsertItem"]; !ok {
 		return nil, errors.New("Unknown query `InsertItem`")
 	}
 
-	return tx.Stmt(stmt.statements["InsertItem"]).Exec(productId, dimensionId, quantity)
+	return tx.Stmt(stmt.statements["InsertItem"]).Exec(listId, productId, dimensionId, quantity, note)
 }
 func (stmt *Queries) RemovePreviousIdempotencyKeys(tx *sql.Tx) (sql.Result, error) {
 	if _, ok := stmt.statements["RemovePreviousIdempotencyKeys"]; !ok {
@@ -525,3 +620,1289 @@ func (stmt *Queries) RemovePreviousIdempotencyKeys(tx *sql.Tx) (sql.Result, erro
 
 	return tx.Stmt(stmt.statements["RemovePreviousIdempotencyKeys"]).Exec()
 }
+
+func (stmt *Queries) InsertList(tx *sql.Tx, householdId int, name string) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertList"]; !ok {
+		return nil, errors.New("Unknown query `InsertList`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertList"]).Exec(householdId, name)
+}
+
+func (stmt *Queries) InsertHousehold(tx *sql.Tx, name string) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertHousehold"]; !ok {
+		return nil, errors.New("Unknown query `InsertHousehold`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertHousehold"]).Exec(name)
+}
+
+func (stmt *Queries) InsertUser(tx *sql.Tx, householdId int, name string, email string) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertUser"]; !ok {
+		return nil, errors.New("Unknown query `InsertUser`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertUser"]).Exec(householdId, name, email)
+}
+
+func (stmt *Queries) InsertCategory(tx *sql.Tx, householdId int, name string) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertCategory"]; !ok {
+		return nil, errors.New("Unknown query `InsertCategory`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertCategory"]).Exec(householdId, name)
+}
+
+func (stmt *Queries) GetPasswordHash(tx *sql.Tx, userId int) (sql.NullString, error) {
+	if _, ok := stmt.statements["GetPasswordHash"]; !ok {
+		return sql.NullString{}, errors.New("Unknown query `GetPasswordHash`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetPasswordHash"]).QueryRow(userId)
+	passwordHash := sql.NullString{}
+	err := row.Scan(&passwordHash)
+	return passwordHash, err
+}
+
+func (stmt *Queries) SetPasswordHash(tx *sql.Tx, userId int, passwordHash string) error {
+	if _, ok := stmt.statements["SetPasswordHash"]; !ok {
+		return errors.New("Unknown query `SetPasswordHash`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["SetPasswordHash"]).Exec(passwordHash, userId)
+	return err
+}
+
+func (stmt *Queries) InsertFailedLogin(tx *sql.Tx, email string) error {
+	if _, ok := stmt.statements["InsertFailedLogin"]; !ok {
+		return errors.New("Unknown query `InsertFailedLogin`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["InsertFailedLogin"]).Exec(email)
+	return err
+}
+
+func (stmt *Queries) CountRecentFailedLogins(tx *sql.Tx, email string) (int, error) {
+	if _, ok := stmt.statements["CountRecentFailedLogins"]; !ok {
+		return 0, errors.New("Unknown query `CountRecentFailedLogins`")
+	}
+
+	row := tx.Stmt(stmt.statements["CountRecentFailedLogins"]).QueryRow(email)
+	count := 0
+	err := row.Scan(&count)
+	return count, err
+}
+
+func (stmt *Queries) RemoveFailedLogins(tx *sql.Tx, email string) error {
+	if _, ok := stmt.statements["RemoveFailedLogins"]; !ok {
+		return errors.New("Unknown query `RemoveFailedLogins`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveFailedLogins"]).Exec(email)
+	return err
+}
+
+func (stmt *Queries) RemovePreviousFailedLogins(tx *sql.Tx) (sql.Result, error) {
+	if _, ok := stmt.statements["RemovePreviousFailedLogins"]; !ok {
+		return nil, errors.New("Unknown query `RemovePreviousFailedLogins`")
+	}
+
+	return tx.Stmt(stmt.statements["RemovePreviousFailedLogins"]).Exec()
+}
+
+func (stmt *Queries) GetPurchases(tx *sql.Tx, householdId int, from time.Time, to time.Time) ([]types.Purchase, error) {
+	if _, ok := stmt.statements["GetPurchases"]; !ok {
+		return nil, errors.New("Unknown query `GetPurchases`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetPurchases"]).Query(householdId, from.UTC().Format("2006-01-02 15:04:05"), to.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	purchases := []types.Purchase{}
+	for rows.Next() {
+		p := types.Purchase{}
+		var gatheredAt string
+		var price sql.NullInt64
+		var dimensionJson string
+
+		err := rows.Scan(&p.ItemId, &p.NameSingular, &p.NamePlural, &p.Quantity, &p.ProductId, &p.UserId, &p.UserName, &gatheredAt, &price, &p.CategoryName, &dimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Price = int(price.Int64)
+
+		p.GatheredAt, err = time.Parse(time.RFC3339, gatheredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &p.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		purchases = append(purchases, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return purchases, nil
+}
+
+func (stmt *Queries) GetProductPrices(tx *sql.Tx, householdId int, productId int) ([]types.Purchase, error) {
+	if _, ok := stmt.statements["GetProductPrices"]; !ok {
+		return nil, errors.New("Unknown query `GetProductPrices`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetProductPrices"]).Query(householdId, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	purchases := []types.Purchase{}
+	for rows.Next() {
+		p := types.Purchase{}
+		var gatheredAt string
+		var price sql.NullInt64
+		var dimensionJson string
+
+		err := rows.Scan(&p.ItemId, &p.NameSingular, &p.NamePlural, &p.Quantity, &p.ProductId, &p.UserId, &p.UserName, &gatheredAt, &price, &p.CategoryName, &dimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Price = int(price.Int64)
+
+		p.GatheredAt, err = time.Parse(time.RFC3339, gatheredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &p.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		purchases = append(purchases, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return purchases, nil
+}
+
+func (stmt *Queries) GetPurchaseDates(tx *sql.Tx, householdId int) ([]string, error) {
+	if _, ok := stmt.statements["GetPurchaseDates"]; !ok {
+		return nil, errors.New("Unknown query `GetPurchaseDates`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetPurchaseDates"]).Query(householdId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dates := []string{}
+	for rows.Next() {
+		var date string
+		err := rows.Scan(&date)
+		if err != nil {
+			return nil, err
+		}
+
+		dates = append(dates, date)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dates, nil
+}
+
+func (stmt *Queries) GetRecurringItems(tx *sql.Tx, listId int) ([]types.RecurringItem, error) {
+	if _, ok := stmt.statements["GetRecurringItems"]; !ok {
+		return nil, errors.New("Unknown query `GetRecurringItems`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetRecurringItems"]).Query(listId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recurringItems := []types.RecurringItem{}
+	for rows.Next() {
+		i := types.RecurringItem{}
+		var nextDueAt string
+		var dimensionJson string
+
+		err := rows.Scan(&i.Id, &i.ListId, &i.UserId, &i.NameSingular, &i.NamePlural, &i.Quantity, &i.ProductId, &i.IntervalDays, &nextDueAt, &dimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		i.NextDueAt, err = time.Parse(time.RFC3339, nextDueAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &i.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		recurringItems = append(recurringItems, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recurringItems, nil
+}
+
+func (stmt *Queries) GetDueRecurringItems(tx *sql.Tx) ([]types.RecurringItem, error) {
+	if _, ok := stmt.statements["GetDueRecurringItems"]; !ok {
+		return nil, errors.New("Unknown query `GetDueRecurringItems`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetDueRecurringItems"]).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recurringItems := []types.RecurringItem{}
+	for rows.Next() {
+		i := types.RecurringItem{}
+
+		err := rows.Scan(&i.Id, &i.ListId, &i.UserId, &i.ProductId, &i.Dimension.Id, &i.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		recurringItems = append(recurringItems, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recurringItems, nil
+}
+
+func (stmt *Queries) InsertRecurringItem(tx *sql.Tx, listId int, productId int, dimensionId int, userId int, quantity int64, intervalDays int, nextDueAt time.Time) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertRecurringItem"]; !ok {
+		return nil, errors.New("Unknown query `InsertRecurringItem`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertRecurringItem"]).Exec(listId, productId, dimensionId, userId, quantity, intervalDays, nextDueAt.UTC().Format("2006-01-02 15:04:05"))
+}
+
+func (stmt *Queries) RemoveRecurringItem(tx *sql.Tx, householdId int, id int) (sql.Result, error) {
+	if _, ok := stmt.statements["RemoveRecurringItem"]; !ok {
+		return nil, errors.New("Unknown query `RemoveRecurringItem`")
+	}
+
+	return tx.Stmt(stmt.statements["RemoveRecurringItem"]).Exec(id, householdId)
+}
+
+func (stmt *Queries) AdvanceRecurringItem(tx *sql.Tx, id int) error {
+	if _, ok := stmt.statements["AdvanceRecurringItem"]; !ok {
+		return errors.New("Unknown query `AdvanceRecurringItem`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["AdvanceRecurringItem"]).Exec(id)
+	return err
+}
+
+func (stmt *Queries) GetProductDetails(tx *sql.Tx, householdId int, id int) (*types.ProductDetails, error) {
+	if _, ok := stmt.statements["GetProductDetails"]; !ok {
+		return nil, errors.New("Unknown query `GetProductDetails`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetProductDetails"]).QueryRow(householdId, id)
+	product := types.ProductDetails{}
+	var categoryIdsJson string
+	var dimensionIdsJson string
+	err := row.Scan(&product.Id, &product.NameSingular, &product.NamePlural, &categoryIdsJson, &dimensionIdsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(categoryIdsJson), &product.CategoryIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(dimensionIdsJson), &product.DimensionIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (stmt *Queries) UpdateProduct(tx *sql.Tx, householdId int, id int, nameSingular string, namePlural string) error {
+	if _, ok := stmt.statements["UpdateProduct"]; !ok {
+		return errors.New("Unknown query `UpdateProduct`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["UpdateProduct"]).Exec(householdId, id, nameSingular, namePlural)
+	return err
+}
+
+func (stmt *Queries) RemoveProductCategories(tx *sql.Tx, productId int) error {
+	if _, ok := stmt.statements["RemoveProductCategories"]; !ok {
+		return errors.New("Unknown query `RemoveProductCategories`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProductCategories"]).Exec(productId)
+	return err
+}
+
+func (stmt *Queries) RemoveProductDimensions(tx *sql.Tx, productId int) error {
+	if _, ok := stmt.statements["RemoveProductDimensions"]; !ok {
+		return errors.New("Unknown query `RemoveProductDimensions`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProductDimensions"]).Exec(productId)
+	return err
+}
+
+func (stmt *Queries) RemoveProductChanges(tx *sql.Tx, productId int) error {
+	if _, ok := stmt.statements["RemoveProductChanges"]; !ok {
+		return errors.New("Unknown query `RemoveProductChanges`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProductChanges"]).Exec(productId)
+	return err
+}
+
+func (stmt *Queries) RemoveProduct(tx *sql.Tx, householdId int, id int) error {
+	if _, ok := stmt.statements["RemoveProduct"]; !ok {
+		return errors.New("Unknown query `RemoveProduct`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProduct"]).Exec(householdId, id)
+	return err
+}
+
+func (stmt *Queries) GetUsedProductDimensions(tx *sql.Tx, productId int) ([]int, error) {
+	if _, ok := stmt.statements["GetUsedProductDimensions"]; !ok {
+		return nil, errors.New("Unknown query `GetUsedProductDimensions`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetUsedProductDimensions"]).Query(productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dimensionIds := []int{}
+	for rows.Next() {
+		var dimensionId int
+		err = rows.Scan(&dimensionId)
+		if err != nil {
+			return nil, err
+		}
+		dimensionIds = append(dimensionIds, dimensionId)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dimensionIds, nil
+}
+
+func (stmt *Queries) CountProductUsage(tx *sql.Tx, productId int) (int, error) {
+	if _, ok := stmt.statements["CountProductUsage"]; !ok {
+		return 0, errors.New("Unknown query `CountProductUsage`")
+	}
+
+	var count int
+	err := tx.Stmt(stmt.statements["CountProductUsage"]).QueryRow(productId).Scan(&count)
+	return count, err
+}
+
+func (stmt *Queries) GetCollidingAddedItems(tx *sql.Tx, mergedProductId int, survivingProductId int) ([]types.CollidingItem, error) {
+	if _, ok := stmt.statements["GetCollidingAddedItems"]; !ok {
+		return nil, errors.New("Unknown query `GetCollidingAddedItems`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetCollidingAddedItems"]).Query(mergedProductId, survivingProductId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []types.CollidingItem{}
+	for rows.Next() {
+		item := types.CollidingItem{}
+		err = rows.Scan(&item.Id, &item.ListId, &item.DimensionId, &item.Quantity, &item.Note)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (stmt *Queries) MoveItems(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["MoveItems"]; !ok {
+		return errors.New("Unknown query `MoveItems`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["MoveItems"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) MoveRecurringItems(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["MoveRecurringItems"]; !ok {
+		return errors.New("Unknown query `MoveRecurringItems`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["MoveRecurringItems"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) MoveProductChanges(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["MoveProductChanges"]; !ok {
+		return errors.New("Unknown query `MoveProductChanges`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["MoveProductChanges"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) CopyProductDimensions(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["CopyProductDimensions"]; !ok {
+		return errors.New("Unknown query `CopyProductDimensions`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["CopyProductDimensions"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) GetCategory(tx *sql.Tx, householdId int, id int) (*types.Category, error) {
+	if _, ok := stmt.statements["GetCategory"]; !ok {
+		return nil, errors.New("Unknown query `GetCategory`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetCategory"]).QueryRow(householdId, id)
+	category := types.Category{}
+	err := row.Scan(&category.Id, &category.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (stmt *Queries) UpdateCategory(tx *sql.Tx, householdId int, id int, name string) error {
+	if _, ok := stmt.statements["UpdateCategory"]; !ok {
+		return errors.New("Unknown query `UpdateCategory`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["UpdateCategory"]).Exec(householdId, id, name)
+	return err
+}
+
+func (stmt *Queries) RemoveCategory(tx *sql.Tx, householdId int, id int) error {
+	if _, ok := stmt.statements["RemoveCategory"]; !ok {
+		return errors.New("Unknown query `RemoveCategory`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveCategory"]).Exec(householdId, id)
+	return err
+}
+
+func (stmt *Queries) RemoveCategoryProducts(tx *sql.Tx, categoryId int) error {
+	if _, ok := stmt.statements["RemoveCategoryProducts"]; !ok {
+		return errors.New("Unknown query `RemoveCategoryProducts`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveCategoryProducts"]).Exec(categoryId)
+	return err
+}
+
+func (stmt *Queries) CountExclusiveCategoryProducts(tx *sql.Tx, categoryId int) (int, error) {
+	if _, ok := stmt.statements["CountExclusiveCategoryProducts"]; !ok {
+		return 0, errors.New("Unknown query `CountExclusiveCategoryProducts`")
+	}
+
+	var count int
+	err := tx.Stmt(stmt.statements["CountExclusiveCategoryProducts"]).QueryRow(categoryId).Scan(&count)
+	return count, err
+}
+
+func (stmt *Queries) ReleaseCategoryOrderings(tx *sql.Tx, householdId int) error {
+	if _, ok := stmt.statements["ReleaseCategoryOrderings"]; !ok {
+		return errors.New("Unknown query `ReleaseCategoryOrderings`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["ReleaseCategoryOrderings"]).Exec(householdId)
+	return err
+}
+
+func (stmt *Queries) SetCategoryOrdering(tx *sql.Tx, householdId int, id int, ordering int) error {
+	if _, ok := stmt.statements["SetCategoryOrdering"]; !ok {
+		return errors.New("Unknown query `SetCategoryOrdering`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["SetCategoryOrdering"]).Exec(householdId, id, ordering)
+	return err
+}
+
+func (stmt *Queries) GetStores(tx *sql.Tx, householdId int) ([]types.Store, error) {
+	if _, ok := stmt.statements["GetStores"]; !ok {
+		return nil, errors.New("Unknown query `GetStores`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetStores"]).Query(householdId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stores := []types.Store{}
+	for rows.Next() {
+		store := types.Store{}
+		err = rows.Scan(&store.Id, &store.Name)
+		if err != nil {
+			return nil, err
+		}
+		stores = append(stores, store)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
+func (stmt *Queries) GetStore(tx *sql.Tx, householdId int, id int) (*types.Store, error) {
+	if _, ok := stmt.statements["GetStore"]; !ok {
+		return nil, errors.New("Unknown query `GetStore`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetStore"]).QueryRow(householdId, id)
+	store := types.Store{}
+	err := row.Scan(&store.Id, &store.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store, nil
+}
+
+func (stmt *Queries) InsertStore(tx *sql.Tx, householdId int, name string) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertStore"]; !ok {
+		return nil, errors.New("Unknown query `InsertStore`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertStore"]).Exec(householdId, name)
+}
+
+func (stmt *Queries) UpdateStore(tx *sql.Tx, householdId int, id int, name string) error {
+	if _, ok := stmt.statements["UpdateStore"]; !ok {
+		return errors.New("Unknown query `UpdateStore`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["UpdateStore"]).Exec(householdId, id, name)
+	return err
+}
+
+func (stmt *Queries) RemoveStore(tx *sql.Tx, householdId int, id int) error {
+	if _, ok := stmt.statements["RemoveStore"]; !ok {
+		return errors.New("Unknown query `RemoveStore`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveStore"]).Exec(householdId, id)
+	return err
+}
+
+func (stmt *Queries) GetStoreCategories(tx *sql.Tx, householdId int, storeId int) ([]types.Category, error) {
+	if _, ok := stmt.statements["GetStoreCategories"]; !ok {
+		return nil, errors.New("Unknown query `GetStoreCategories`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetStoreCategories"]).Query(householdId, storeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []types.Category{}
+	for rows.Next() {
+		category := types.Category{}
+		err = rows.Scan(&category.Id, &category.Name)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (stmt *Queries) InsertStoreCategory(tx *sql.Tx, storeId int, categoryId int, ordering int) error {
+	if _, ok := stmt.statements["InsertStoreCategory"]; !ok {
+		return errors.New("Unknown query `InsertStoreCategory`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["InsertStoreCategory"]).Exec(storeId, categoryId, ordering)
+	return err
+}
+
+func (stmt *Queries) RemoveStoreCategories(tx *sql.Tx, storeId int) error {
+	if _, ok := stmt.statements["RemoveStoreCategories"]; !ok {
+		return errors.New("Unknown query `RemoveStoreCategories`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveStoreCategories"]).Exec(storeId)
+	return err
+}
+
+func (stmt *Queries) RemoveCategoryStores(tx *sql.Tx, categoryId int) error {
+	if _, ok := stmt.statements["RemoveCategoryStores"]; !ok {
+		return errors.New("Unknown query `RemoveCategoryStores`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveCategoryStores"]).Exec(categoryId)
+	return err
+}
+
+func (stmt *Queries) SetItemNote(tx *sql.Tx, itemId int64, note string) error {
+	if _, ok := stmt.statements["SetItemNote"]; !ok {
+		return errors.New("Unknown query `SetItemNote`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["SetItemNote"]).Exec(note, itemId)
+	return err
+}
+
+func (stmt *Queries) SetLatestItemChangePrice(tx *sql.Tx, itemId int, price int) error {
+	if _, ok := stmt.statements["SetLatestItemChangePrice"]; !ok {
+		return errors.New("Unknown query `SetLatestItemChangePrice`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["SetLatestItemChangePrice"]).Exec(itemId, price)
+	return err
+}
+
+func (stmt *Queries) SearchProducts(tx *sql.Tx, householdId int, trigrams []string, limit int) ([]types.Product, error) {
+	if _, ok := stmt.statements["SearchProducts"]; !ok {
+		return nil, errors.New("Unknown query `SearchProducts`")
+	}
+
+	trigramsJson, err := json.Marshal(trigrams)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Stmt(stmt.statements["SearchProducts"]).Query(householdId, string(trigramsJson), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []types.Product{}
+	for rows.Next() {
+		p := types.Product{}
+		err = rows.Scan(&p.Id, &p.NameSingular, &p.NamePlural)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (stmt *Queries) GetUnindexedProducts(tx *sql.Tx) ([]types.Product, error) {
+	if _, ok := stmt.statements["GetUnindexedProducts"]; !ok {
+		return nil, errors.New("Unknown query `GetUnindexedProducts`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetUnindexedProducts"]).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []types.Product{}
+	for rows.Next() {
+		p := types.Product{}
+		err = rows.Scan(&p.Id, &p.NameSingular, &p.NamePlural)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (stmt *Queries) InsertProductTrigram(tx *sql.Tx, productId int64, trigram string) error {
+	if _, ok := stmt.statements["InsertProductTrigram"]; !ok {
+		return errors.New("Unknown query `InsertProductTrigram`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["InsertProductTrigram"]).Exec(productId, trigram)
+	return err
+}
+
+func (stmt *Queries) RemoveProductTrigrams(tx *sql.Tx, productId int64) error {
+	if _, ok := stmt.statements["RemoveProductTrigrams"]; !ok {
+		return errors.New("Unknown query `RemoveProductTrigrams`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProductTrigrams"]).Exec(productId)
+	return err
+}
+
+func (stmt *Queries) GetProductAliases(tx *sql.Tx, productId int) ([]types.ProductAlias, error) {
+	if _, ok := stmt.statements["GetProductAliases"]; !ok {
+		return nil, errors.New("Unknown query `GetProductAliases`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetProductAliases"]).Query(productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []types.ProductAlias{}
+	for rows.Next() {
+		alias := types.ProductAlias{}
+		err = rows.Scan(&alias.Id, &alias.ProductId, &alias.Name)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+func (stmt *Queries) GetProductAlias(tx *sql.Tx, householdId int, id int) (*types.ProductAlias, error) {
+	if _, ok := stmt.statements["GetProductAlias"]; !ok {
+		return nil, errors.New("Unknown query `GetProductAlias`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetProductAlias"]).QueryRow(householdId, id)
+	alias := types.ProductAlias{}
+	err := row.Scan(&alias.Id, &alias.ProductId, &alias.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
+
+func (stmt *Queries) GetProductAliasByName(tx *sql.Tx, householdId int, name string) (*types.ProductAlias, error) {
+	if _, ok := stmt.statements["GetProductAliasByName"]; !ok {
+		return nil, errors.New("Unknown query `GetProductAliasByName`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetProductAliasByName"]).QueryRow(householdId, name)
+	alias := types.ProductAlias{}
+	err := row.Scan(&alias.Id, &alias.ProductId, &alias.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
+
+func (stmt *Queries) InsertProductAlias(tx *sql.Tx, householdId int, productId int, name string) error {
+	if _, ok := stmt.statements["InsertProductAlias"]; !ok {
+		return errors.New("Unknown query `InsertProductAlias`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["InsertProductAlias"]).Exec(householdId, productId, name)
+	return err
+}
+
+func (stmt *Queries) RemoveProductAlias(tx *sql.Tx, householdId int, id int) error {
+	if _, ok := stmt.statements["RemoveProductAlias"]; !ok {
+		return errors.New("Unknown query `RemoveProductAlias`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProductAlias"]).Exec(householdId, id)
+	return err
+}
+
+func (stmt *Queries) RemoveProductAliases(tx *sql.Tx, productId int) error {
+	if _, ok := stmt.statements["RemoveProductAliases"]; !ok {
+		return errors.New("Unknown query `RemoveProductAliases`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveProductAliases"]).Exec(productId)
+	return err
+}
+
+func (stmt *Queries) MoveProductAliases(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["MoveProductAliases"]; !ok {
+		return errors.New("Unknown query `MoveProductAliases`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["MoveProductAliases"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) GetRecipes(tx *sql.Tx, householdId int) ([]types.Recipe, error) {
+	if _, ok := stmt.statements["GetRecipes"]; !ok {
+		return nil, errors.New("Unknown query `GetRecipes`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetRecipes"]).Query(householdId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []types.Recipe{}
+	for rows.Next() {
+		recipe := types.Recipe{}
+		err = rows.Scan(&recipe.Id, &recipe.Name, &recipe.Servings)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recipes, nil
+}
+
+func (stmt *Queries) GetRecipe(tx *sql.Tx, householdId int, id int) (*types.Recipe, error) {
+	if _, ok := stmt.statements["GetRecipe"]; !ok {
+		return nil, errors.New("Unknown query `GetRecipe`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetRecipe"]).QueryRow(householdId, id)
+	recipe := types.Recipe{}
+	err := row.Scan(&recipe.Id, &recipe.Name, &recipe.Servings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func (stmt *Queries) InsertRecipe(tx *sql.Tx, householdId int, name string, servings int) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertRecipe"]; !ok {
+		return nil, errors.New("Unknown query `InsertRecipe`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertRecipe"]).Exec(householdId, name, servings)
+}
+
+func (stmt *Queries) UpdateRecipe(tx *sql.Tx, householdId int, id int, name string, servings int) error {
+	if _, ok := stmt.statements["UpdateRecipe"]; !ok {
+		return errors.New("Unknown query `UpdateRecipe`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["UpdateRecipe"]).Exec(householdId, id, name, servings)
+	return err
+}
+
+func (stmt *Queries) RemoveRecipe(tx *sql.Tx, householdId int, id int) error {
+	if _, ok := stmt.statements["RemoveRecipe"]; !ok {
+		return errors.New("Unknown query `RemoveRecipe`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveRecipe"]).Exec(householdId, id)
+	return err
+}
+
+func (stmt *Queries) GetRecipeIngredients(tx *sql.Tx, recipeId int) ([]types.RecipeIngredient, error) {
+	if _, ok := stmt.statements["GetRecipeIngredients"]; !ok {
+		return nil, errors.New("Unknown query `GetRecipeIngredients`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetRecipeIngredients"]).Query(recipeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ingredients := []types.RecipeIngredient{}
+	for rows.Next() {
+		i := types.RecipeIngredient{}
+		var dimensionJson string
+
+		err := rows.Scan(&i.Id, &i.RecipeId, &i.ProductId, &i.NameSingular, &i.NamePlural, &i.Quantity, &dimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &i.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		ingredients = append(ingredients, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ingredients, nil
+}
+
+func (stmt *Queries) InsertRecipeIngredient(tx *sql.Tx, recipeId int, productId int, dimensionId int, quantity int64) error {
+	if _, ok := stmt.statements["InsertRecipeIngredient"]; !ok {
+		return errors.New("Unknown query `InsertRecipeIngredient`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["InsertRecipeIngredient"]).Exec(recipeId, productId, dimensionId, quantity)
+	return err
+}
+
+func (stmt *Queries) RemoveRecipeIngredient(tx *sql.Tx, recipeId int, id int) error {
+	if _, ok := stmt.statements["RemoveRecipeIngredient"]; !ok {
+		return errors.New("Unknown query `RemoveRecipeIngredient`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveRecipeIngredient"]).Exec(recipeId, id)
+	return err
+}
+
+func (stmt *Queries) RemoveRecipeIngredients(tx *sql.Tx, recipeId int) error {
+	if _, ok := stmt.statements["RemoveRecipeIngredients"]; !ok {
+		return errors.New("Unknown query `RemoveRecipeIngredients`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveRecipeIngredients"]).Exec(recipeId)
+	return err
+}
+
+func (stmt *Queries) MoveRecipeIngredients(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["MoveRecipeIngredients"]; !ok {
+		return errors.New("Unknown query `MoveRecipeIngredients`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["MoveRecipeIngredients"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) GetStockLevels(tx *sql.Tx, householdId int) ([]types.StockLevel, error) {
+	if _, ok := stmt.statements["GetStockLevels"]; !ok {
+		return nil, errors.New("Unknown query `GetStockLevels`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetStockLevels"]).Query(householdId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stockLevels := []types.StockLevel{}
+	for rows.Next() {
+		s := types.StockLevel{}
+		var dimensionJson string
+
+		err := rows.Scan(&s.Id, &s.ProductId, &s.NameSingular, &s.NamePlural, &s.Quantity, &s.MinimumQuantity, &s.ListId, &dimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &s.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		stockLevels = append(stockLevels, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stockLevels, nil
+}
+
+func (stmt *Queries) GetStockLevel(tx *sql.Tx, householdId int, id int) (*types.StockLevel, error) {
+	if _, ok := stmt.statements["GetStockLevel"]; !ok {
+		return nil, errors.New("Unknown query `GetStockLevel`")
+	}
+
+	row := tx.Stmt(stmt.statements["GetStockLevel"]).QueryRow(householdId, id)
+	s := types.StockLevel{}
+	var dimensionJson string
+
+	err := row.Scan(&s.Id, &s.ProductId, &s.NameSingular, &s.NamePlural, &s.Quantity, &s.MinimumQuantity, &s.ListId, &dimensionJson)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(dimensionJson), &s.Dimension)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (stmt *Queries) AddToStockLevel(tx *sql.Tx, productId int, dimensionId int, quantity int64) error {
+	if _, ok := stmt.statements["AddToStockLevel"]; !ok {
+		return errors.New("Unknown query `AddToStockLevel`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["AddToStockLevel"]).Exec(productId, dimensionId, quantity)
+	return err
+}
+
+func (stmt *Queries) RemoveFromStockLevel(tx *sql.Tx, productId int, dimensionId int, quantity int64) error {
+	if _, ok := stmt.statements["RemoveFromStockLevel"]; !ok {
+		return errors.New("Unknown query `RemoveFromStockLevel`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveFromStockLevel"]).Exec(productId, dimensionId, quantity)
+	return err
+}
+
+func (stmt *Queries) SetStockLevelMinimum(tx *sql.Tx, id int, minimumQuantity int64, listId int) error {
+	if _, ok := stmt.statements["SetStockLevelMinimum"]; !ok {
+		return errors.New("Unknown query `SetStockLevelMinimum`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["SetStockLevelMinimum"]).Exec(id, minimumQuantity, listId)
+	return err
+}
+
+func (stmt *Queries) MoveStockLevels(tx *sql.Tx, fromProductId int, toProductId int) error {
+	if _, ok := stmt.statements["MoveStockLevels"]; !ok {
+		return errors.New("Unknown query `MoveStockLevels`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["MoveStockLevels"]).Exec(fromProductId, toProductId)
+	return err
+}
+
+func (stmt *Queries) RemoveStockLevels(tx *sql.Tx, productId int) error {
+	if _, ok := stmt.statements["RemoveStockLevels"]; !ok {
+		return errors.New("Unknown query `RemoveStockLevels`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RemoveStockLevels"]).Exec(productId)
+	return err
+}
+
+func (stmt *Queries) GetItemAudit(tx *sql.Tx, householdId int, userId int, productId int, state string, from time.Time, to time.Time, limit int) ([]types.AuditEntry, error) {
+	if _, ok := stmt.statements["GetItemAudit"]; !ok {
+		return nil, errors.New("Unknown query `GetItemAudit`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetItemAudit"]).Query(householdId, userId, productId, state, from.UTC().Format("2006-01-02 15:04:05"), to.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []types.AuditEntry{}
+	for rows.Next() {
+		e := types.AuditEntry{Kind: "item"}
+		var recordedAt string
+		var dimensionJson string
+		var previousDimensionJson string
+
+		err := rows.Scan(&e.Id, &recordedAt, &e.UserId, &e.UserName, &e.ProductId, &e.NameSingular, &e.NamePlural, &e.ListName, &e.PreviousState, &e.State, &e.PreviousQuantity, &e.Quantity, &e.Note, &e.Price, &dimensionJson, &previousDimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		e.RecordedAt, err = time.Parse(time.RFC3339, recordedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &e.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(previousDimensionJson), &e.PreviousDimension)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (stmt *Queries) GetProductAudit(tx *sql.Tx, householdId int, userId int, productId int, from time.Time, to time.Time, limit int) ([]types.AuditEntry, error) {
+	if _, ok := stmt.statements["GetProductAudit"]; !ok {
+		return nil, errors.New("Unknown query `GetProductAudit`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetProductAudit"]).Query(householdId, userId, productId, from.UTC().Format("2006-01-02 15:04:05"), to.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []types.AuditEntry{}
+	for rows.Next() {
+		e := types.AuditEntry{Kind: "product"}
+		var recordedAt string
+
+		err := rows.Scan(&e.Id, &recordedAt, &e.UserId, &e.UserName, &e.ProductId, &e.NameSingular, &e.NamePlural, &e.PreviousNameSingular, &e.PreviousNamePlural)
+		if err != nil {
+			return nil, err
+		}
+
+		e.RecordedAt, err = time.Parse(time.RFC3339, recordedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (stmt *Queries) InsertItemAction(tx *sql.Tx, userId int, kind string, revertedActionId int64) (sql.Result, error) {
+	if _, ok := stmt.statements["InsertItemAction"]; !ok {
+		return nil, errors.New("Unknown query `InsertItemAction`")
+	}
+
+	return tx.Stmt(stmt.statements["InsertItemAction"]).Exec(userId, kind, revertedActionId)
+}
+
+func (stmt *Queries) GetActionItems(tx *sql.Tx, actionId int64, userId int) ([]types.RevertedItem, error) {
+	if _, ok := stmt.statements["GetActionItems"]; !ok {
+		return nil, errors.New("Unknown query `GetActionItems`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetActionItems"]).Query(actionId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []types.RevertedItem{}
+	for rows.Next() {
+		var i types.RevertedItem
+		err := rows.Scan(&i.ItemId, &i.State, &i.DimensionId, &i.Quantity, &i.Note, &i.ConflictingUserName)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (stmt *Queries) RestoreItem(tx *sql.Tx, itemId int, state string, dimensionId int, quantity int, note string) error {
+	if _, ok := stmt.statements["RestoreItem"]; !ok {
+		return errors.New("Unknown query `RestoreItem`")
+	}
+
+	_, err := tx.Stmt(stmt.statements["RestoreItem"]).Exec(itemId, state, dimensionId, quantity, note)
+	return err
+}
+
+func (stmt *Queries) GetActionStack(tx *sql.Tx, userId int, kind string, limit int) ([]types.AuditEntry, error) {
+	if _, ok := stmt.statements["GetActionStack"]; !ok {
+		return nil, errors.New("Unknown query `GetActionStack`")
+	}
+
+	rows, err := tx.Stmt(stmt.statements["GetActionStack"]).Query(userId, kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []types.AuditEntry{}
+	for rows.Next() {
+		e := types.AuditEntry{Kind: "item"}
+		var recordedAt string
+		var dimensionJson string
+		var previousDimensionJson string
+
+		err := rows.Scan(&e.ActionId, &e.Id, &recordedAt, &e.UserId, &e.UserName, &e.ProductId, &e.NameSingular, &e.NamePlural, &e.ListName, &e.PreviousState, &e.State, &e.PreviousQuantity, &e.Quantity, &e.Note, &dimensionJson, &previousDimensionJson)
+		if err != nil {
+			return nil, err
+		}
+
+		e.RecordedAt, err = time.Parse(time.RFC3339, recordedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(dimensionJson), &e.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(previousDimensionJson), &e.PreviousDimension)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
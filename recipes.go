@@ -0,0 +1,161 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+
+	"stravid.com/besserliste/types"
+)
+
+var errRecipeWithoutIngredients = errors.New("Rezept hat noch keine Zutaten.")
+
+// addRecipe creates a recipe without ingredients, those are added one at a time afterwards.
+// Invalid input is reported through formErrors and leaves the recipes untouched.
+func (env *Environment) addRecipe(tx *sql.Tx, householdId int, name string, servings string, formErrors map[string]string) (int64, error) {
+	parsedServings := validateRecipe(name, servings, formErrors)
+	if len(formErrors) != 0 {
+		return 0, nil
+	}
+
+	result, err := env.queries.InsertRecipe(tx, householdId, name, parsedServings)
+	if err != nil {
+		if isDuplicateRecipeName(err, formErrors) {
+			return 0, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	return result.LastInsertId()
+}
+
+// editRecipe changes name and servings, the quantities of the ingredients stay as they are and apply to the new servings.
+func (env *Environment) editRecipe(tx *sql.Tx, householdId int, recipeId int, name string, servings string, formErrors map[string]string) error {
+	parsedServings := validateRecipe(name, servings, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	err := env.queries.UpdateRecipe(tx, householdId, recipeId, name, parsedServings)
+	if err != nil && isDuplicateRecipeName(err, formErrors) {
+		return nil
+	}
+
+	return err
+}
+
+// removeRecipe deletes a recipe of the household, recipes which do not exist (anymore) are ignored.
+func (env *Environment) removeRecipe(tx *sql.Tx, householdId int, recipeId int) error {
+	_, err := env.queries.GetRecipe(tx, householdId, recipeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	err = env.queries.RemoveRecipeIngredients(tx, recipeId)
+	if err != nil {
+		return err
+	}
+
+	return env.queries.RemoveRecipe(tx, householdId, recipeId)
+}
+
+// addRecipeIngredient validates the quantity like addItem does and stores it for all servings of the recipe.
+func (env *Environment) addRecipeIngredient(tx *sql.Tx, recipe *types.Recipe, product *types.SelectedProduct, unitId string, amount string, formErrors map[string]string) error {
+	unit, dimension, ok := findUnit(product, unitId)
+	if !ok {
+		formErrors["unit_id"] = "Maßeinheit wählen"
+	}
+
+	parsedQuantity := parseQuantity(amount, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	baseQuantity := toBaseQuantity(parsedQuantity, unit, 0, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	return env.queries.InsertRecipeIngredient(tx, recipe.Id, product.Id, dimension.Id, baseQuantity)
+}
+
+// removeRecipeIngredient ignores recipes and ingredients which do not exist (anymore).
+func (env *Environment) removeRecipeIngredient(tx *sql.Tx, householdId int, recipeId int, ingredientId int) error {
+	_, err := env.queries.GetRecipe(tx, householdId, recipeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	return env.queries.RemoveRecipeIngredient(tx, recipeId, ingredientId)
+}
+
+// addRecipeToList scales the ingredients to the given servings and sums them into the added items of the list.
+// The returned events must be published once the transaction is committed.
+func (env *Environment) addRecipeToList(tx *sql.Tx, userId int, listId int, recipe *types.Recipe, servings string, formErrors map[string]string) ([]itemEvent, error) {
+	parsedServings := parseServings(servings, formErrors)
+	if len(formErrors) != 0 {
+		return nil, nil
+	}
+
+	ingredients, err := env.queries.GetRecipeIngredients(tx, recipe.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ingredients) == 0 {
+		return nil, errRecipeWithoutIngredients
+	}
+
+	action := newItemAction(userId, "do")
+	events := []itemEvent{}
+	for _, ingredient := range ingredients {
+		quantity := types.ScaleQuantity(ingredient.Quantity, parsedServings, recipe.Servings)
+
+		itemId, err := env.addBaseQuantity(tx, action, listId, ingredient.ProductId, ingredient.Dimension.Id, int64(quantity), "")
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, itemEvent{ListId: listId, ItemId: int(itemId), State: "added", UserId: userId})
+	}
+
+	return events, nil
+}
+
+func validateRecipe(name string, servings string, formErrors map[string]string) int {
+	if name == "" {
+		formErrors["name"] = "Namen angeben"
+	} else if utf8.RuneCountInString(name) > 40 {
+		formErrors["name"] = "Kürzeren Namen angeben (maximal 40 Zeichen)"
+	}
+
+	return parseServings(servings, formErrors)
+}
+
+func parseServings(servings string, formErrors map[string]string) int {
+	parsedServings, err := strconv.Atoi(servings)
+	if err != nil || parsedServings < 1 || parsedServings > 100 {
+		formErrors["servings"] = "Anzahl der Portionen angeben (1 bis 100)"
+	}
+
+	return parsedServings
+}
+
+func isDuplicateRecipeName(err error, formErrors map[string]string) bool {
+	if err.Error() == "UNIQUE constraint failed: index 'idx_recipes_name'" {
+		formErrors["name"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"stravid.com/besserliste/types"
+)
+
+// addRecurringItem validates the rule like addItem validates an item and stores it for the current list.
+func (env *Environment) addRecurringItem(tx *sql.Tx, userId int, listId int, product *types.SelectedProduct, unitId string, amount string, intervalDays string, startDate string, formErrors map[string]string) error {
+	unit, dimension, ok := findUnit(product, unitId)
+	if !ok {
+		formErrors["unit_id"] = "Maßeinheit wählen"
+	}
+
+	parsedQuantity := parseQuantity(amount, formErrors)
+
+	parsedIntervalDays, err := strconv.Atoi(intervalDays)
+	if err != nil || parsedIntervalDays < 1 || parsedIntervalDays > 365 {
+		formErrors["interval_days"] = "Abstand in Tagen angeben (1 bis 365)"
+	}
+
+	today := time.Now().Format("2006-01-02")
+	nextDueAt, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		formErrors["start_date"] = "Datum angeben"
+	} else if startDate < today {
+		formErrors["start_date"] = "Heutiges oder späteres Datum angeben"
+	}
+
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	baseQuantity := toBaseQuantity(parsedQuantity, unit, 0, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	_, err = env.queries.InsertRecurringItem(tx, listId, product.Id, dimension.Id, userId, baseQuantity, parsedIntervalDays, nextDueAt)
+	return err
+}
+
+// recurringItemsScheduler periodically puts due recurring items on their list until ctx is cancelled.
+func (env *Environment) recurringItemsScheduler(ctx context.Context) {
+	for {
+		err := env.addDueRecurringItems()
+		if err != nil {
+			panic(fmt.Sprintf("recurringItemsScheduler: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(60 * time.Second):
+		}
+	}
+}
+
+func (env *Environment) addDueRecurringItems() error {
+	tx, err := env.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	recurringItems, err := env.queries.GetDueRecurringItems(tx)
+	if err != nil {
+		return err
+	}
+
+	events := []itemEvent{}
+	for _, recurringItem := range recurringItems {
+		// Changes are recorded in the name of whoever set up the rule, but they are not on the undo stack of that user.
+		itemId, err := env.addBaseQuantity(tx, newItemAction(recurringItem.UserId, "system"), recurringItem.ListId, recurringItem.ProductId, recurringItem.Dimension.Id, int64(recurringItem.Quantity), "")
+		if err != nil {
+			return err
+		}
+
+		err = env.queries.AdvanceRecurringItem(tx, recurringItem.Id)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, itemEvent{ListId: recurringItem.ListId, ItemId: int(itemId), State: "added", UserId: recurringItem.UserId})
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		env.events.publish(event)
+	}
+
+	return nil
+}
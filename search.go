@@ -0,0 +1,82 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"stravid.com/besserliste/types"
+)
+
+// Enough to pick from while typing, the rest only shows up once the query gets more specific.
+const maxSearchResults = 12
+
+// searchProducts finds products whose names are similar to the query, an empty query finds nothing.
+func (env *Environment) searchProducts(tx *sql.Tx, householdId int, query string) ([]types.Product, error) {
+	trigrams := types.SearchTrigrams(strings.TrimLeft(query, " "))
+	if len(trigrams) == 0 {
+		return []types.Product{}, nil
+	}
+
+	return env.queries.SearchProducts(tx, householdId, trigrams, maxSearchResults)
+}
+
+// indexProduct replaces the search trigrams of a product with the trigrams of its names and aliases.
+func (env *Environment) indexProduct(tx *sql.Tx, productId int64, nameSingular string, namePlural string) error {
+	aliases, err := env.queries.GetProductAliases(tx, int(productId))
+	if err != nil {
+		return err
+	}
+
+	names := []string{nameSingular, namePlural}
+	for _, alias := range aliases {
+		names = append(names, alias.Name)
+	}
+
+	err = env.queries.RemoveProductTrigrams(tx, productId)
+	if err != nil {
+		return err
+	}
+
+	for _, trigram := range types.Trigrams(strings.Join(names, " ")) {
+		err = env.queries.InsertProductTrigram(tx, productId, trigram)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexProducts adds the products missing from the search index, imports insert products without their trigrams.
+func (env *Environment) indexProducts() error {
+	tx, err := env.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	products, err := env.queries.GetUnindexedProducts(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		err = env.indexProduct(tx, int64(product.Id), product.NameSingular, product.NamePlural)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	if len(products) > 0 {
+		log.Println(fmt.Sprintf("Indexed %d products for search", len(products)))
+	}
+
+	return nil
+}
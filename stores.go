@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"unicode/utf8"
+)
+
+var errStoreNotFound = errors.New("Geschäft existiert nicht.")
+
+// addStore creates a store which starts out with the category order of the household.
+// Invalid input is reported through formErrors and leaves the stores untouched.
+func (env *Environment) addStore(tx *sql.Tx, householdId int, name string, formErrors map[string]string) (int64, error) {
+	validateStoreName(name, formErrors)
+	if len(formErrors) != 0 {
+		return 0, nil
+	}
+
+	result, err := env.queries.InsertStore(tx, householdId, name)
+	if err != nil {
+		if isDuplicateStoreName(err, formErrors) {
+			return 0, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	storeId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	categories, err := env.queries.GetCategories(tx, householdId)
+	if err != nil {
+		return 0, err
+	}
+
+	for index, category := range categories {
+		err = env.queries.InsertStoreCategory(tx, int(storeId), category.Id, index+1)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return storeId, nil
+}
+
+func (env *Environment) renameStore(tx *sql.Tx, householdId int, storeId int, name string, formErrors map[string]string) error {
+	validateStoreName(name, formErrors)
+	if len(formErrors) != 0 {
+		return nil
+	}
+
+	err := env.queries.UpdateStore(tx, householdId, storeId, name)
+	if err != nil && isDuplicateStoreName(err, formErrors) {
+		return nil
+	}
+
+	return err
+}
+
+// removeStore deletes a store of the household, stores which do not exist (anymore) are ignored.
+func (env *Environment) removeStore(tx *sql.Tx, householdId int, storeId int) error {
+	_, err := env.queries.GetStore(tx, householdId, storeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	err = env.queries.RemoveStoreCategories(tx, storeId)
+	if err != nil {
+		return err
+	}
+
+	return env.queries.RemoveStore(tx, householdId, storeId)
+}
+
+// reorderStoreCategories replaces the aisle sequence of a store with the given order of all categories of the household.
+func (env *Environment) reorderStoreCategories(tx *sql.Tx, householdId int, storeId int, categoryIds []string) error {
+	_, err := env.queries.GetStore(tx, householdId, storeId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errStoreNotFound
+		} else {
+			return err
+		}
+	}
+
+	categories, err := env.queries.GetCategories(tx, householdId)
+	if err != nil {
+		return err
+	}
+
+	orderedIds, err := parseCategoryOrder(categories, categoryIds)
+	if err != nil {
+		return err
+	}
+
+	err = env.queries.RemoveStoreCategories(tx, storeId)
+	if err != nil {
+		return err
+	}
+
+	for index, categoryId := range orderedIds {
+		err = env.queries.InsertStoreCategory(tx, storeId, categoryId, index+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateStoreName(name string, formErrors map[string]string) {
+	if name == "" {
+		formErrors["name"] = "Namen angeben"
+	} else if utf8.RuneCountInString(name) > 20 {
+		formErrors["name"] = "Kürzeren Namen angeben (maximal 20 Zeichen)"
+	}
+}
+
+func isDuplicateStoreName(err error, formErrors map[string]string) bool {
+	if err.Error() == "UNIQUE constraint failed: index 'idx_stores_name'" {
+		formErrors["name"] = "Anderen Namen angeben (ist bereits in Verwendung)"
+		return true
+	}
+
+	return false
+}
@@ -2,24 +2,68 @@ package types
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
+type Household struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 type User struct {
-	Id   int
-	Name string
+	Id          int    `json:"id"`
+	HouseholdId int    `json:"household_id"`
+	Name        string `json:"name"`
+}
+
+type List struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 type Category struct {
-	Id   int
-	Name string
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Store struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 type Product struct {
-	Id           int
-	NameSingular string
-	NamePlural   string
+	Id           int    `json:"id"`
+	NameSingular string `json:"name_singular"`
+	NamePlural   string `json:"name_plural"`
+}
+
+type ProductDetails struct {
+	Id           int    `json:"id"`
+	NameSingular string `json:"name_singular"`
+	NamePlural   string `json:"name_plural"`
+	CategoryIds  []int  `json:"category_ids"`
+	DimensionIds []int  `json:"dimension_ids"`
+}
+
+// ProductAlias is another name people use for a product, like `Paradeiser` for `Tomaten`.
+type ProductAlias struct {
+	Id        int    `json:"id"`
+	ProductId int    `json:"product_id"`
+	Name      string `json:"name"`
+}
+
+// CollidingItem is an added item of a product being merged for which the surviving product already has an added item on the same list and dimension.
+type CollidingItem struct {
+	Id          int    `json:"id"`
+	ListId      int    `json:"list_id"`
+	DimensionId int    `json:"dimension_id"`
+	Quantity    int    `json:"quantity"`
+	Note        string `json:"note"`
 }
 
 type Dimension struct {
@@ -43,29 +87,116 @@ type SelectedProduct struct {
 }
 
 type AddedItem struct {
-	Id           int    `json:"id"`
-	NameSingular string `json:"name_singular"`
-	NamePlural   string `json:"name_plural"`
-	Quantity     int
-	ProductId    int
+	Id           int       `json:"id"`
+	NameSingular string    `json:"name_singular"`
+	NamePlural   string    `json:"name_plural"`
+	Quantity     int       `json:"quantity"`
+	Note         string    `json:"note"`
+	ProductId    int       `json:"product_id"`
 	Dimension    Dimension `json:"dimension"`
 }
 
 type SelectedItem struct {
-	Id           int    `json:"id"`
-	NameSingular string `json:"name_singular"`
-	NamePlural   string `json:"name_plural"`
+	Id           int       `json:"id"`
+	NameSingular string    `json:"name_singular"`
+	NamePlural   string    `json:"name_plural"`
+	Quantity     int       `json:"quantity"`
+	Note         string    `json:"note"`
+	State        string    `json:"state"`
+	ListId       int       `json:"list_id"`
+	ProductId    int       `json:"product_id"`
+	Dimension    Dimension `json:"dimension"`
+}
+
+// RecurringItem puts its quantity on the list every IntervalDays days.
+type RecurringItem struct {
+	Id           int
+	ListId       int
+	UserId       int
+	NameSingular string
+	NamePlural   string
 	Quantity     int
-	State        string
 	ProductId    int
+	Dimension    Dimension
+	IntervalDays int
+	NextDueAt    time.Time
+}
+
+// Recipe is a dish whose ingredients can be put on the list at once, scaled to the number of servings.
+type Recipe struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Servings int    `json:"servings"`
+}
+
+// RecipeIngredient is the quantity of a product needed for all servings of a recipe.
+type RecipeIngredient struct {
+	Id           int       `json:"id"`
+	RecipeId     int       `json:"recipe_id"`
+	ProductId    int       `json:"product_id"`
+	NameSingular string    `json:"name_singular"`
+	NamePlural   string    `json:"name_plural"`
+	Quantity     int       `json:"quantity"`
 	Dimension    Dimension `json:"dimension"`
 }
 
+// StockLevel is how much of a product the household has at home, counted in the base unit of one dimension.
+// A MinimumQuantity of 0 means the product is not refilled automatically, otherwise the missing quantity goes on ListId.
+type StockLevel struct {
+	Id              int       `json:"id"`
+	ProductId       int       `json:"product_id"`
+	NameSingular    string    `json:"name_singular"`
+	NamePlural      string    `json:"name_plural"`
+	Quantity        int       `json:"quantity"`
+	MinimumQuantity int       `json:"minimum_quantity"`
+	ListId          int       `json:"list_id"`
+	Dimension       Dimension `json:"dimension"`
+}
+
+// Purchase is an item gathered during a shopping trip.
+// Price is the total in cents, 0 means nobody entered one.
+type Purchase struct {
+	ItemId       int
+	NameSingular string
+	NamePlural   string
+	Quantity     int
+	ProductId    int
+	Dimension    Dimension
+	UserId       int
+	UserName     string
+	GatheredAt   time.Time
+	Price        int
+	CategoryName string
+}
+
+// Suggestion is a product which was bought regularly and whose usual interval between two purchases has elapsed.
+// Quantity and Dimension are those of the last purchase.
+type Suggestion struct {
+	ProductId       int
+	NameSingular    string
+	NamePlural      string
+	Quantity        int
+	Dimension       Dimension
+	LastPurchasedAt time.Time
+	IntervalDays    int
+	ElapsedDays     int
+}
+
+// Trip are the purchases one user gathered without a longer break in between.
+type Trip struct {
+	UserId    int
+	UserName  string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Purchases []Purchase
+}
+
 func (i *AddedItem) FormattedQuantity() string {
 	return FormattedQuantity(i.Quantity, i.Dimension.Units)
 }
 
-func FormattedQuantity(quantity int, units []Unit) string {
+// BestFittingUnit is the unit with the smallest quantity that is still at least 1, like `1,5 l` instead of `1500 ml`.
+func BestFittingUnit(quantity int, units []Unit) Unit {
 	floatQuantity := float64(quantity)
 	var bestFittingUnit Unit
 
@@ -82,7 +213,18 @@ func FormattedQuantity(quantity int, units []Unit) string {
 		}
 	}
 
-	formattedQuantity := strings.Replace(strconv.FormatFloat(bestFittingUnit.ConversionFromBase*floatQuantity, 'f', -1, 32), ".", ",", -1)
+	return bestFittingUnit
+}
+
+// FormattedAmount is the quantity in the unit with a decimal comma, like it is entered into forms.
+func FormattedAmount(quantity int, unit Unit) string {
+	return strings.Replace(strconv.FormatFloat(unit.ConversionFromBase*float64(quantity), 'f', -1, 32), ".", ",", -1)
+}
+
+func FormattedQuantity(quantity int, units []Unit) string {
+	floatQuantity := float64(quantity)
+	bestFittingUnit := BestFittingUnit(quantity, units)
+	formattedQuantity := FormattedAmount(quantity, bestFittingUnit)
 
 	if bestFittingUnit.ConversionFromBase*floatQuantity > 1 {
 		return fmt.Sprintf("%s %s", formattedQuantity, bestFittingUnit.NamePlural)
@@ -99,10 +241,562 @@ func (i *AddedItem) FormattedName() string {
 	}
 }
 
-func (p *Product) SearchTerm() string {
-	if p.NameSingular != p.NamePlural {
-		return strings.ToLower(fmt.Sprintf("%s %s", p.NameSingular, p.NamePlural))
+func (i *SelectedItem) FormattedQuantity() string {
+	return FormattedQuantity(i.Quantity, i.Dimension.Units)
+}
+
+func (i *RecipeIngredient) FormattedQuantity() string {
+	return FormattedQuantity(i.Quantity, i.Dimension.Units)
+}
+
+func (i *RecipeIngredient) FormattedName() string {
+	if i.Quantity == 1 {
+		return i.NameSingular
 	} else {
-		return strings.ToLower(p.NamePlural)
+		return i.NamePlural
 	}
 }
+
+// ScaleQuantity converts the quantity of an ingredient from the servings of the recipe to the wanted servings.
+// Quantities are rounded up, half an onion still means buying a whole one.
+func ScaleQuantity(quantity int, servings int, recipeServings int) int {
+	scaled := (quantity*servings + recipeServings - 1) / recipeServings
+	if scaled < 1 {
+		return 1
+	}
+
+	return scaled
+}
+
+func (s *StockLevel) FormattedName() string {
+	if s.Quantity == 1 {
+		return s.NameSingular
+	} else {
+		return s.NamePlural
+	}
+}
+
+func (s *StockLevel) FormattedQuantity() string {
+	if s.Quantity == 0 {
+		return "aufgebraucht"
+	}
+
+	return FormattedQuantity(s.Quantity, s.Dimension.Units)
+}
+
+func (s *StockLevel) FormattedMinimumQuantity() string {
+	return FormattedQuantity(s.MinimumQuantity, s.Dimension.Units)
+}
+
+// MissingQuantity is how much has to be bought to get back to the minimum.
+func (s *StockLevel) MissingQuantity() int {
+	if s.MinimumQuantity == 0 || s.Quantity >= s.MinimumQuantity {
+		return 0
+	}
+
+	return s.MinimumQuantity - s.Quantity
+}
+
+func (i *RecurringItem) FormattedQuantity() string {
+	return FormattedQuantity(i.Quantity, i.Dimension.Units)
+}
+
+func (i *RecurringItem) FormattedName() string {
+	if i.Quantity == 1 {
+		return i.NameSingular
+	} else {
+		return i.NamePlural
+	}
+}
+
+func (i *RecurringItem) FormattedInterval() string {
+	return FormattedInterval(i.IntervalDays)
+}
+
+// FormattedInterval describes a number of days like `wöchentlich` or `alle 3 Tage`.
+func FormattedInterval(days int) string {
+	if days == 1 {
+		return "täglich"
+	} else if days == 7 {
+		return "wöchentlich"
+	} else if days%7 == 0 {
+		return fmt.Sprintf("alle %d Wochen", days/7)
+	} else {
+		return fmt.Sprintf("alle %d Tage", days)
+	}
+}
+
+func (i *RecurringItem) FormattedNextDueAt() string {
+	return i.NextDueAt.Local().Format("02.01.2006")
+}
+
+func (p *Purchase) FormattedQuantity() string {
+	return FormattedQuantity(p.Quantity, p.Dimension.Units)
+}
+
+func (p *Purchase) FormattedName() string {
+	if p.Quantity == 1 {
+		return p.NameSingular
+	} else {
+		return p.NamePlural
+	}
+}
+
+// FormattedTime is the local time span of the trip like `14:05 – 14:32 Uhr`.
+func (t *Trip) FormattedTime() string {
+	startedAt := t.StartedAt.Local().Format("15:04")
+	endedAt := t.EndedAt.Local().Format("15:04")
+
+	if startedAt == endedAt {
+		return fmt.Sprintf("%s Uhr", startedAt)
+	} else {
+		return fmt.Sprintf("%s – %s Uhr", startedAt, endedAt)
+	}
+}
+
+// GroupTrips clusters purchases per user, a gap longer than maxGap between two purchases starts a new trip.
+// Trips are ordered by start with the most recent one first.
+func GroupTrips(purchases []Purchase, maxGap time.Duration) []Trip {
+	sorted := make([]Purchase, len(purchases))
+	copy(sorted, purchases)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].GatheredAt.Before(sorted[b].GatheredAt)
+	})
+
+	trips := []Trip{}
+	currentTrip := map[int]int{}
+	for _, purchase := range sorted {
+		index, ok := currentTrip[purchase.UserId]
+		if !ok || purchase.GatheredAt.Sub(trips[index].EndedAt) > maxGap {
+			trips = append(trips, Trip{
+				UserId:    purchase.UserId,
+				UserName:  purchase.UserName,
+				StartedAt: purchase.GatheredAt,
+			})
+			index = len(trips) - 1
+			currentTrip[purchase.UserId] = index
+		}
+
+		trips[index].EndedAt = purchase.GatheredAt
+		trips[index].Purchases = append(trips[index].Purchases, purchase)
+	}
+
+	sort.SliceStable(trips, func(a, b int) bool {
+		return trips[a].StartedAt.After(trips[b].StartedAt)
+	})
+
+	return trips
+}
+
+// FormatPrice formats cents like `2,49 €`.
+func FormatPrice(cents int) string {
+	return fmt.Sprintf("%d,%02d €", cents/100, cents%100)
+}
+
+func (p *Purchase) FormattedPrice() string {
+	return FormatPrice(p.Price)
+}
+
+// FormattedUnitPrice is the price per largest unit of the dimension like `4,00 € / kg`, which makes purchases of different quantities comparable.
+func (p *Purchase) FormattedUnitPrice() string {
+	var largestUnit Unit
+	for _, unit := range p.Dimension.Units {
+		if largestUnit.Id == 0 || unit.ConversionToBase > largestUnit.ConversionToBase {
+			largestUnit = unit
+		}
+	}
+
+	unitQuantity := float64(p.Quantity) * largestUnit.ConversionFromBase
+	if unitQuantity == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s / %s", FormatPrice(int(math.Round(float64(p.Price)/unitQuantity))), largestUnit.NameSingular)
+}
+
+// Total sums the prices of the purchases, purchases without a price are skipped.
+func (t *Trip) Total() int {
+	total := 0
+	for _, purchase := range t.Purchases {
+		total += purchase.Price
+	}
+
+	return total
+}
+
+func (t *Trip) FormattedTotal() string {
+	return FormatPrice(t.Total())
+}
+
+// Spending is the amount spent on one category or by one user.
+type Spending struct {
+	Name  string
+	Total int
+}
+
+func (s *Spending) FormattedTotal() string {
+	return FormatPrice(s.Total)
+}
+
+// SpendingReport sums the prices of purchases per category and per user.
+// Unpriced counts the purchases nobody entered a price for, they are not part of any total.
+type SpendingReport struct {
+	Total      int
+	Unpriced   int
+	Categories []Spending
+	Users      []Spending
+}
+
+func (r *SpendingReport) FormattedTotal() string {
+	return FormatPrice(r.Total)
+}
+
+// SummarizeSpending builds the report with the most expensive category and user first.
+func SummarizeSpending(purchases []Purchase) SpendingReport {
+	report := SpendingReport{
+		Categories: []Spending{},
+		Users:      []Spending{},
+	}
+	categories := map[string]int{}
+	users := map[int]*Spending{}
+
+	for _, purchase := range purchases {
+		if purchase.Price == 0 {
+			report.Unpriced += 1
+			continue
+		}
+
+		categoryName := purchase.CategoryName
+		if categoryName == "" {
+			categoryName = "Ohne Kategorie"
+		}
+
+		report.Total += purchase.Price
+		categories[categoryName] += purchase.Price
+		if _, ok := users[purchase.UserId]; !ok {
+			users[purchase.UserId] = &Spending{Name: purchase.UserName}
+		}
+		users[purchase.UserId].Total += purchase.Price
+	}
+
+	for name, total := range categories {
+		report.Categories = append(report.Categories, Spending{Name: name, Total: total})
+	}
+
+	for _, spending := range users {
+		report.Users = append(report.Users, *spending)
+	}
+
+	sortSpendings(report.Categories)
+	sortSpendings(report.Users)
+
+	return report
+}
+
+func sortSpendings(spendings []Spending) {
+	sort.Slice(spendings, func(a, b int) bool {
+		if spendings[a].Total != spendings[b].Total {
+			return spendings[a].Total > spendings[b].Total
+		}
+
+		return spendings[a].Name < spendings[b].Name
+	})
+}
+
+var searchReplacer = strings.NewReplacer("ä", "a", "ö", "o", "ü", "u", "ß", "ss")
+
+// searchWords lowercases the text, folds umlauts and splits it into words so `Müsli` and `musli` look the same.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(searchReplacer.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func wordTrigrams(word string, trigrams map[string]bool) {
+	runes := []rune(word)
+	for i := 0; i+3 <= len(runes); i++ {
+		trigrams[string(runes[i:i+3])] = true
+	}
+}
+
+func sortedTrigrams(trigrams map[string]bool) []string {
+	result := []string{}
+	for trigram := range trigrams {
+		result = append(result, trigram)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// Trigrams are the distinct three letter sequences of every word in the text.
+// Words are padded with two spaces in front and one behind, so beginnings and ends of words count as well.
+func Trigrams(text string) []string {
+	trigrams := map[string]bool{}
+	for _, word := range searchWords(text) {
+		wordTrigrams("  "+word+" ", trigrams)
+	}
+
+	return sortedTrigrams(trigrams)
+}
+
+// SearchTrigrams are the Trigrams of a query typed so far.
+// The last word is probably incomplete and therefore not padded behind unless the query ends with a space.
+func SearchTrigrams(query string) []string {
+	trigrams := map[string]bool{}
+	words := searchWords(query)
+	for i, word := range words {
+		if i == len(words)-1 && !strings.HasSuffix(query, " ") {
+			wordTrigrams("  "+word, trigrams)
+		} else {
+			wordTrigrams("  "+word+" ", trigrams)
+		}
+	}
+
+	return sortedTrigrams(trigrams)
+}
+
+// A product needs at least this many purchases on different days before its interval is trusted.
+const minSuggestionPurchases = 3
+
+// Products not bought for this many of their intervals are probably not wanted anymore.
+const maxSuggestionOverdue = 3
+
+// SuggestPurchases predicts which products are probably running out.
+// The interval of a product is the median number of days between its purchases, several purchases on the same day count once.
+// Suggestions are ordered with the most overdue product first.
+func SuggestPurchases(purchases []Purchase, now time.Time, excludedProductIds map[int]bool) []Suggestion {
+	sorted := make([]Purchase, len(purchases))
+	copy(sorted, purchases)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].GatheredAt.Before(sorted[b].GatheredAt)
+	})
+
+	days := map[int][]time.Time{}
+	lastPurchases := map[int]Purchase{}
+	for _, purchase := range sorted {
+		if excludedProductIds[purchase.ProductId] {
+			continue
+		}
+
+		day := startOfDay(purchase.GatheredAt, now.Location())
+		productDays := days[purchase.ProductId]
+		if len(productDays) == 0 || !productDays[len(productDays)-1].Equal(day) {
+			days[purchase.ProductId] = append(productDays, day)
+		}
+		lastPurchases[purchase.ProductId] = purchase
+	}
+
+	today := startOfDay(now, now.Location())
+	suggestions := []Suggestion{}
+	for productId, productDays := range days {
+		if len(productDays) < minSuggestionPurchases {
+			continue
+		}
+
+		gaps := []int{}
+		for i := 1; i < len(productDays); i++ {
+			gaps = append(gaps, daysBetween(productDays[i-1], productDays[i]))
+		}
+		sort.Ints(gaps)
+		intervalDays := gaps[len(gaps)/2]
+
+		elapsedDays := daysBetween(productDays[len(productDays)-1], today)
+		if elapsedDays < intervalDays || elapsedDays > maxSuggestionOverdue*intervalDays {
+			continue
+		}
+
+		lastPurchase := lastPurchases[productId]
+		suggestions = append(suggestions, Suggestion{
+			ProductId:       productId,
+			NameSingular:    lastPurchase.NameSingular,
+			NamePlural:      lastPurchase.NamePlural,
+			Quantity:        lastPurchase.Quantity,
+			Dimension:       lastPurchase.Dimension,
+			LastPurchasedAt: lastPurchase.GatheredAt,
+			IntervalDays:    intervalDays,
+			ElapsedDays:     elapsedDays,
+		})
+	}
+
+	sort.Slice(suggestions, func(a, b int) bool {
+		overdueA := float64(suggestions[a].ElapsedDays) / float64(suggestions[a].IntervalDays)
+		overdueB := float64(suggestions[b].ElapsedDays) / float64(suggestions[b].IntervalDays)
+		if overdueA != overdueB {
+			return overdueA > overdueB
+		}
+
+		return suggestions[a].NamePlural < suggestions[b].NamePlural
+	})
+
+	return suggestions
+}
+
+func startOfDay(t time.Time, location *time.Location) time.Time {
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+}
+
+// daysBetween rounds, so days with a daylight saving time change count as whole days.
+func daysBetween(from time.Time, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func (s *Suggestion) FormattedName() string {
+	if s.Quantity == 1 {
+		return s.NameSingular
+	} else {
+		return s.NamePlural
+	}
+}
+
+func (s *Suggestion) FormattedQuantity() string {
+	return FormattedQuantity(s.Quantity, s.Dimension.Units)
+}
+
+func (s *Suggestion) FormattedInterval() string {
+	return FormattedInterval(s.IntervalDays)
+}
+
+// UnitId and Amount put the quantity of the last purchase into the add item form.
+func (s *Suggestion) UnitId() int {
+	return BestFittingUnit(s.Quantity, s.Dimension.Units).Id
+}
+
+func (s *Suggestion) Amount() string {
+	return FormattedAmount(s.Quantity, BestFittingUnit(s.Quantity, s.Dimension.Units))
+}
+
+// AuditEntry is one recorded change of an item or a product together with what it looked like before.
+// Item changes have an empty PreviousState when they put the item on the list, product changes have an empty PreviousNamePlural when they created the product.
+type AuditEntry struct {
+	Kind                 string
+	Id                   int
+	ActionId             int
+	RecordedAt           time.Time
+	UserId               int
+	UserName             string
+	ProductId            int
+	NameSingular         string
+	NamePlural           string
+	PreviousNameSingular string
+	PreviousNamePlural   string
+	ListName             string
+	PreviousState        string
+	State                string
+	PreviousQuantity     int
+	Quantity             int
+	PreviousDimension    Dimension
+	Dimension            Dimension
+	Note                 string
+	Price                int
+}
+
+// MergeAuditEntries interleaves item and product changes, newest first, and keeps at most limit entries.
+func MergeAuditEntries(itemEntries []AuditEntry, productEntries []AuditEntry, limit int) []AuditEntry {
+	entries := append(append([]AuditEntry{}, itemEntries...), productEntries...)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.After(entries[j].RecordedAt)
+	})
+
+	if limit >= 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries
+}
+
+func (e *AuditEntry) FormattedName() string {
+	return e.NamePlural
+}
+
+func (e *AuditEntry) FormattedRecordedAt() string {
+	return e.RecordedAt.Local().Format("02.01.2006 15:04")
+}
+
+func (e *AuditEntry) FormattedQuantity() string {
+	if e.Kind != "item" {
+		return ""
+	}
+
+	return FormattedQuantity(e.Quantity, e.Dimension.Units)
+}
+
+func (e *AuditEntry) FormattedPrice() string {
+	if e.Price == 0 {
+		return ""
+	}
+
+	return FormatPrice(e.Price)
+}
+
+// Description names the transition of docs/item-state-machine.txt or the kind of product change.
+func (e *AuditEntry) Description() string {
+	if e.Kind == "product" {
+		if e.PreviousNamePlural == "" {
+			return "Produkt angelegt"
+		} else if e.PreviousNameSingular != e.NameSingular || e.PreviousNamePlural != e.NamePlural {
+			return fmt.Sprintf("Umbenannt von %s", e.PreviousNamePlural)
+		} else {
+			return "Produkt bearbeitet"
+		}
+	}
+
+	switch e.PreviousState + ">" + e.State {
+	case ">added":
+		return "Aufgeschrieben"
+	case "added>gathered":
+		return "Abgehakt"
+	case "gathered>added":
+		return "Abhaken rückgängig gemacht"
+	case "added>removed":
+		return "Entfernt"
+	case "removed>added":
+		return "Entfernen rückgängig gemacht"
+	case "added>added":
+		if e.PreviousQuantity != e.Quantity || e.PreviousDimension.Id != e.Dimension.Id {
+			return fmt.Sprintf("Menge geändert von %s", FormattedQuantity(e.PreviousQuantity, e.PreviousDimension.Units))
+		} else {
+			return "Notiz geändert"
+		}
+	default:
+		return fmt.Sprintf("Von %s zu %s", e.PreviousState, e.State)
+	}
+}
+
+// ItemAction groups the item changes one undo or redo brings back, for example all ingredients of a recipe.
+type ItemAction struct {
+	Id      int
+	Changes []AuditEntry
+}
+
+// GroupItemActions keeps the order of the entries, which arrive sorted by action.
+func GroupItemActions(entries []AuditEntry) []ItemAction {
+	actions := []ItemAction{}
+	for _, entry := range entries {
+		if len(actions) == 0 || actions[len(actions)-1].Id != entry.ActionId {
+			actions = append(actions, ItemAction{Id: entry.ActionId})
+		}
+
+		last := &actions[len(actions)-1]
+		last.Changes = append(last.Changes, entry)
+	}
+
+	return actions
+}
+
+func (a *ItemAction) FormattedRecordedAt() string {
+	return a.Changes[0].FormattedRecordedAt()
+}
+
+// RevertedItem is what an item looked like before an action changed it.
+// ConflictingUserName is set when someone changed the item afterwards, reverting would silently drop that change.
+type RevertedItem struct {
+	ItemId              int
+	State               string
+	DimensionId         int
+	Quantity            int
+	Note                string
+	ConflictingUserName string
+}
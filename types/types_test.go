@@ -1,7 +1,9 @@
 package types
 
 import (
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestFormattedQuantity(t *testing.T) {
@@ -56,3 +58,314 @@ func TestFormattedQuantity(t *testing.T) {
 		t.Fatalf("%s instead of %s", r, "1,25 l")
 	}
 }
+
+func TestGroupTrips(t *testing.T) {
+	start := time.Date(2022, 5, 7, 10, 0, 0, 0, time.UTC)
+	purchases := []Purchase{
+		{ItemId: 1, UserId: 1, GatheredAt: start},
+		{ItemId: 2, UserId: 2, GatheredAt: start.Add(5 * time.Minute)},
+		{ItemId: 3, UserId: 1, GatheredAt: start.Add(20 * time.Minute)},
+		{ItemId: 4, UserId: 1, GatheredAt: start.Add(3 * time.Hour)},
+	}
+
+	trips := GroupTrips(purchases, time.Hour)
+
+	if len(trips) != 3 {
+		t.Fatalf("%d trips instead of %d", len(trips), 3)
+	}
+
+	if r := trips[0].Purchases[0].ItemId; r != 4 {
+		t.Fatalf("trip starting with item %d instead of %d", r, 4)
+	}
+
+	if r := trips[1].UserId; r != 2 {
+		t.Fatalf("trip of user %d instead of %d", r, 2)
+	}
+
+	if r := len(trips[2].Purchases); r != 2 {
+		t.Fatalf("%d purchases instead of %d", r, 2)
+	}
+
+	if r := trips[2].EndedAt; !r.Equal(start.Add(20 * time.Minute)) {
+		t.Fatalf("trip ended at %s instead of %s", r, start.Add(20*time.Minute))
+	}
+}
+
+func TestFormattedInterval(t *testing.T) {
+	expectations := map[int]string{
+		1:  "täglich",
+		3:  "alle 3 Tage",
+		7:  "wöchentlich",
+		14: "alle 2 Wochen",
+		30: "alle 30 Tage",
+	}
+
+	for intervalDays, expected := range expectations {
+		item := RecurringItem{IntervalDays: intervalDays}
+		if r := item.FormattedInterval(); r != expected {
+			t.Fatalf("%s instead of %s", r, expected)
+		}
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	expectations := map[int]string{
+		0:      "0,00 €",
+		5:      "0,05 €",
+		249:    "2,49 €",
+		100000: "1000,00 €",
+	}
+
+	for cents, expected := range expectations {
+		if r := FormatPrice(cents); r != expected {
+			t.Fatalf("%s instead of %s", r, expected)
+		}
+	}
+}
+
+func TestFormattedUnitPrice(t *testing.T) {
+	weight := Dimension{
+		Id:   2,
+		Name: "Gewicht",
+		Units: []Unit{
+			{Id: 2, NameSingular: "Gramm", NamePlural: "Gramm", ConversionToBase: 1, ConversionFromBase: 1},
+			{Id: 3, NameSingular: "Kilogramm", NamePlural: "Kilogramm", ConversionToBase: 1000, ConversionFromBase: 0.001},
+		},
+	}
+
+	purchase := Purchase{Quantity: 500, Dimension: weight, Price: 199}
+
+	if r := purchase.FormattedUnitPrice(); r != "3,98 € / Kilogramm" {
+		t.Fatalf("%s instead of %s", r, "3,98 € / Kilogramm")
+	}
+}
+
+func TestSummarizeSpending(t *testing.T) {
+	purchases := []Purchase{
+		{ItemId: 1, UserId: 1, UserName: "Hannah", CategoryName: "Obst & Gemüse", Price: 199},
+		{ItemId: 2, UserId: 2, UserName: "David", CategoryName: "Milchprodukte", Price: 129},
+		{ItemId: 3, UserId: 1, UserName: "Hannah", CategoryName: "Obst & Gemüse", Price: 250},
+		{ItemId: 4, UserId: 2, UserName: "David", CategoryName: "Milchprodukte"},
+		{ItemId: 5, UserId: 2, UserName: "David", Price: 99},
+	}
+
+	report := SummarizeSpending(purchases)
+
+	if r := report.Total; r != 677 {
+		t.Fatalf("total of %d instead of %d", r, 677)
+	}
+
+	if r := report.Unpriced; r != 1 {
+		t.Fatalf("%d unpriced purchases instead of %d", r, 1)
+	}
+
+	expectedCategories := []Spending{
+		{Name: "Obst & Gemüse", Total: 449},
+		{Name: "Milchprodukte", Total: 129},
+		{Name: "Ohne Kategorie", Total: 99},
+	}
+	if !reflect.DeepEqual(report.Categories, expectedCategories) {
+		t.Fatalf("%v instead of %v", report.Categories, expectedCategories)
+	}
+
+	expectedUsers := []Spending{
+		{Name: "Hannah", Total: 449},
+		{Name: "David", Total: 228},
+	}
+	if !reflect.DeepEqual(report.Users, expectedUsers) {
+		t.Fatalf("%v instead of %v", report.Users, expectedUsers)
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	expected := []string{"  m", " mu", "li ", "mus", "sli", "usl"}
+	if r := Trigrams("Müsli"); !reflect.DeepEqual(r, expected) {
+		t.Fatalf("%v instead of %v", r, expected)
+	}
+
+	expected = []string{"  a", "  b", " ap", " bi", "ap ", "bio", "io "}
+	if r := Trigrams("Bio-Ap"); !reflect.DeepEqual(r, expected) {
+		t.Fatalf("%v instead of %v", r, expected)
+	}
+}
+
+func TestSearchTrigrams(t *testing.T) {
+	expectations := map[string][]string{
+		"to":      {"  t", " to"},
+		"tom":     {"  t", " to", "tom"},
+		"tom ":    {"  t", " to", "om ", "tom"},
+		"bio tom": {"  b", "  t", " bi", " to", "bio", "io ", "tom"},
+		"":        {},
+	}
+
+	for query, expected := range expectations {
+		if r := SearchTrigrams(query); !reflect.DeepEqual(r, expected) {
+			t.Fatalf("%v instead of %v for `%s`", r, expected, query)
+		}
+	}
+}
+
+func TestSuggestPurchases(t *testing.T) {
+	now := time.Date(2022, 3, 20, 18, 0, 0, 0, time.UTC)
+	day := func(daysAgo int, hour int) time.Time {
+		return time.Date(2022, 3, 20-daysAgo, hour, 0, 0, 0, time.UTC)
+	}
+
+	purchases := []Purchase{
+		// Milk every 7 days, last bought 8 days ago.
+		{ProductId: 1, NamePlural: "Milch", Quantity: 2000, GatheredAt: day(29, 10)},
+		{ProductId: 1, NamePlural: "Milch", Quantity: 2000, GatheredAt: day(22, 10)},
+		{ProductId: 1, NamePlural: "Milch", Quantity: 1000, GatheredAt: day(15, 10)},
+		{ProductId: 1, NamePlural: "Milch", Quantity: 1000, GatheredAt: day(8, 10)},
+		// Bread every 2 days, last bought today.
+		{ProductId: 2, NamePlural: "Brote", Quantity: 1, GatheredAt: day(4, 9)},
+		{ProductId: 2, NamePlural: "Brote", Quantity: 1, GatheredAt: day(2, 9)},
+		{ProductId: 2, NamePlural: "Brote", Quantity: 1, GatheredAt: day(0, 9)},
+		// Apples bought twice on the same day only count once, so there are not enough purchases.
+		{ProductId: 3, NamePlural: "Äpfel", Quantity: 6, GatheredAt: day(20, 9)},
+		{ProductId: 3, NamePlural: "Äpfel", Quantity: 6, GatheredAt: day(20, 17)},
+		{ProductId: 3, NamePlural: "Äpfel", Quantity: 6, GatheredAt: day(10, 9)},
+		// Tomatoes every 3 days, but not anymore for a month.
+		{ProductId: 4, NamePlural: "Tomaten", Quantity: 4, GatheredAt: day(36, 9)},
+		{ProductId: 4, NamePlural: "Tomaten", Quantity: 4, GatheredAt: day(33, 9)},
+		{ProductId: 4, NamePlural: "Tomaten", Quantity: 4, GatheredAt: day(30, 9)},
+		// Butter every 5 days, already on the list.
+		{ProductId: 5, NamePlural: "Butter", Quantity: 1, GatheredAt: day(20, 9)},
+		{ProductId: 5, NamePlural: "Butter", Quantity: 1, GatheredAt: day(15, 9)},
+		{ProductId: 5, NamePlural: "Butter", Quantity: 1, GatheredAt: day(10, 9)},
+	}
+
+	suggestions := SuggestPurchases(purchases, now, map[int]bool{5: true})
+
+	if len(suggestions) != 1 {
+		t.Fatalf("%d suggestions instead of %d", len(suggestions), 1)
+	}
+
+	if r := suggestions[0].ProductId; r != 1 {
+		t.Fatalf("product %d suggested instead of %d", r, 1)
+	}
+
+	if r := suggestions[0].IntervalDays; r != 7 {
+		t.Fatalf("interval of %d days instead of %d", r, 7)
+	}
+
+	if r := suggestions[0].Quantity; r != 1000 {
+		t.Fatalf("quantity of %d instead of %d", r, 1000)
+	}
+}
+
+func TestScaleQuantity(t *testing.T) {
+	expectations := []struct {
+		quantity       int
+		servings       int
+		recipeServings int
+		expected       int
+	}{
+		{500, 4, 4, 500},
+		{500, 2, 4, 250},
+		{3, 2, 4, 2},
+		{1, 1, 4, 1},
+		{250, 3, 4, 188},
+		{200, 8, 4, 400},
+	}
+
+	for _, e := range expectations {
+		if r := ScaleQuantity(e.quantity, e.servings, e.recipeServings); r != e.expected {
+			t.Fatalf("%d instead of %d for %d scaled from %d to %d servings", r, e.expected, e.quantity, e.recipeServings, e.servings)
+		}
+	}
+}
+
+func TestMissingQuantity(t *testing.T) {
+	expectations := []struct {
+		quantity        int
+		minimumQuantity int
+		expected        int
+	}{
+		{0, 0, 0},
+		{500, 0, 0},
+		{0, 1000, 1000},
+		{250, 1000, 750},
+		{1000, 1000, 0},
+		{1500, 1000, 0},
+	}
+
+	for _, e := range expectations {
+		stockLevel := StockLevel{Quantity: e.quantity, MinimumQuantity: e.minimumQuantity}
+		if r := stockLevel.MissingQuantity(); r != e.expected {
+			t.Fatalf("%d instead of %d for %d in stock with a minimum of %d", r, e.expected, e.quantity, e.minimumQuantity)
+		}
+	}
+}
+
+func TestAuditEntryDescription(t *testing.T) {
+	pieces := Dimension{Id: 1, Units: []Unit{{Id: 1, NameSingular: "Stück", NamePlural: "Stück", ConversionToBase: 1, ConversionFromBase: 1}}}
+	weight := Dimension{Id: 2, Units: []Unit{{Id: 2, NameSingular: "g", NamePlural: "g", ConversionToBase: 1, ConversionFromBase: 1}}}
+
+	expectations := []struct {
+		entry    AuditEntry
+		expected string
+	}{
+		{AuditEntry{Kind: "item", PreviousState: "", State: "added"}, "Aufgeschrieben"},
+		{AuditEntry{Kind: "item", PreviousState: "added", State: "gathered"}, "Abgehakt"},
+		{AuditEntry{Kind: "item", PreviousState: "gathered", State: "added"}, "Abhaken rückgängig gemacht"},
+		{AuditEntry{Kind: "item", PreviousState: "added", State: "removed"}, "Entfernt"},
+		{AuditEntry{Kind: "item", PreviousState: "removed", State: "added"}, "Entfernen rückgängig gemacht"},
+		{AuditEntry{Kind: "item", PreviousState: "added", State: "added", PreviousQuantity: 2, Quantity: 3, PreviousDimension: pieces, Dimension: pieces}, "Menge geändert von 2 Stück"},
+		{AuditEntry{Kind: "item", PreviousState: "added", State: "added", PreviousQuantity: 2, Quantity: 2, PreviousDimension: pieces, Dimension: weight}, "Menge geändert von 2 Stück"},
+		{AuditEntry{Kind: "item", PreviousState: "added", State: "added", PreviousQuantity: 2, Quantity: 2, PreviousDimension: pieces, Dimension: pieces}, "Notiz geändert"},
+		{AuditEntry{Kind: "product", NameSingular: "Tomate", NamePlural: "Tomaten"}, "Produkt angelegt"},
+		{AuditEntry{Kind: "product", NameSingular: "Paradeiser", NamePlural: "Paradeiser", PreviousNameSingular: "Tomate", PreviousNamePlural: "Tomaten"}, "Umbenannt von Tomaten"},
+		{AuditEntry{Kind: "product", NameSingular: "Tomate", NamePlural: "Tomaten", PreviousNameSingular: "Tomate", PreviousNamePlural: "Tomaten"}, "Produkt bearbeitet"},
+	}
+
+	for _, e := range expectations {
+		if r := e.entry.Description(); r != e.expected {
+			t.Fatalf("`%s` instead of `%s` for %+v", r, e.expected, e.entry)
+		}
+	}
+}
+
+func TestMergeAuditEntries(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2022, 3, 1, 10, minute, 0, 0, time.UTC)
+	}
+
+	itemEntries := []AuditEntry{{Kind: "item", Id: 2, RecordedAt: at(30)}, {Kind: "item", Id: 1, RecordedAt: at(10)}}
+	productEntries := []AuditEntry{{Kind: "product", Id: 1, RecordedAt: at(20)}}
+
+	merged := MergeAuditEntries(itemEntries, productEntries, -1)
+	order := []string{}
+	for _, entry := range merged {
+		order = append(order, entry.Kind)
+	}
+
+	if !reflect.DeepEqual(order, []string{"item", "product", "item"}) || merged[0].Id != 2 {
+		t.Fatalf("Unexpected order %v", order)
+	}
+
+	if len(MergeAuditEntries(itemEntries, productEntries, 2)) != 2 {
+		t.Fatalf("Limit was not applied")
+	}
+}
+
+func TestGroupItemActions(t *testing.T) {
+	entries := []AuditEntry{
+		{ActionId: 7, Id: 20},
+		{ActionId: 7, Id: 21},
+		{ActionId: 5, Id: 12},
+	}
+
+	actions := GroupItemActions(entries)
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(actions))
+	}
+
+	if actions[0].Id != 7 || len(actions[0].Changes) != 2 || actions[1].Id != 5 || actions[1].Changes[0].Id != 12 {
+		t.Fatalf("Unexpected grouping %+v", actions)
+	}
+
+	if len(GroupItemActions([]AuditEntry{})) != 0 {
+		t.Fatalf("Expected no actions")
+	}
+}
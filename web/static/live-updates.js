@@ -0,0 +1,47 @@
+// Keeps the element marked with `data-live-updates` in sync with changes other people make to the current list.
+// Every event refetches the page and swaps in the freshly rendered element, so the markup stays in the templates.
+(function() {
+  var container = document.querySelector('[data-live-updates]');
+  if (!container || !window.EventSource || !window.fetch || !window.DOMParser) {
+    return;
+  }
+
+  var loading = false;
+  var pending = false;
+
+  function refresh() {
+    if (loading) {
+      pending = true;
+      return;
+    }
+
+    loading = true;
+    fetch(window.location.href, { credentials: 'same-origin' })
+      .then(function(response) {
+        if (!response.ok) {
+          throw new Error(response.statusText);
+        }
+
+        return response.text();
+      })
+      .then(function(html) {
+        var page = new DOMParser().parseFromString(html, 'text/html');
+        var freshContainer = page.querySelector('[data-live-updates]');
+        if (freshContainer) {
+          container.innerHTML = freshContainer.innerHTML;
+          document.dispatchEvent(new CustomEvent('besserliste:updated'));
+        }
+      })
+      .catch(function() {})
+      .then(function() {
+        loading = false;
+        if (pending) {
+          pending = false;
+          refresh();
+        }
+      });
+  }
+
+  var source = new EventSource('/events');
+  source.addEventListener('item', refresh);
+})();
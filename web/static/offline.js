@@ -0,0 +1,82 @@
+// Registers the service worker and shows which actions are still waiting for a connection.
+(function() {
+  if (!('serviceWorker' in navigator) || !window.indexedDB) {
+    return;
+  }
+
+  navigator.serviceWorker.register('/service-worker.js');
+
+  // Forms marked with `data-offline-queue` are shared by all items on the page, so every submission needs its own idempotency key.
+  // Otherwise a second action queued from the same page would look like a replay of the first one.
+  document.addEventListener('submit', function(event) {
+    var form = event.target;
+    if (!form.hasAttribute || !form.hasAttribute('data-offline-queue')) {
+      return;
+    }
+
+    var input = form.querySelector('input[name=_idempotency_key]');
+    if (input) {
+      input.value = idempotencyKey();
+    }
+  }, true);
+
+  navigator.serviceWorker.addEventListener('message', function(event) {
+    if (event.data === 'queue-changed') {
+      markQueuedItems();
+    }
+  });
+
+  document.addEventListener('besserliste:updated', markQueuedItems);
+
+  window.addEventListener('online', function() {
+    navigator.serviceWorker.ready.then(function(registration) {
+      registration.active.postMessage('replay');
+    });
+  });
+
+  markQueuedItems();
+
+  function markQueuedItems() {
+    var open = indexedDB.open('besserliste', 1);
+    open.onupgradeneeded = function() {
+      open.result.createObjectStore('queue', { keyPath: 'id', autoIncrement: true });
+    };
+    open.onsuccess = function() {
+      var db = open.result;
+      var request = db.transaction('queue', 'readonly').objectStore('queue').getAll();
+      request.onsuccess = function() {
+        db.close();
+
+        var queued = request.result.map(function(entry) {
+          return {
+            action: new URL(entry.url).pathname,
+            itemId: new URLSearchParams(entry.body).get('item_id')
+          };
+        });
+
+        var buttons = document.querySelectorAll('button[name=item_id]');
+        for (var i = 0; i < buttons.length; i++) {
+          var button = buttons[i];
+          var form = document.getElementById(button.getAttribute('form'));
+          var action = form ? new URL(form.action).pathname : '';
+          var isQueued = queued.some(function(entry) {
+            return entry.action === action && entry.itemId === button.value;
+          });
+
+          button.disabled = isQueued;
+          button.closest('li').classList.toggle('queued', isQueued);
+        }
+      };
+    };
+  }
+
+  function idempotencyKey() {
+    var bytes = new Uint8Array(24);
+    crypto.getRandomValues(bytes);
+    var binary = '';
+    for (var i = 0; i < bytes.length; i++) {
+      binary += String.fromCharCode(bytes[i]);
+    }
+    return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').slice(0, 32);
+  }
+})();
@@ -0,0 +1,68 @@
+// Lets categories be dragged into the walking order of the store.
+// Uses pointer events so dragging works with touch as well as with a mouse, the buttons next to each category remain as fallback.
+(function() {
+  var form = document.querySelector('form[data-reorder]');
+  if (!form || !window.PointerEvent) {
+    return;
+  }
+
+  var list = form.querySelector('ol');
+  var dragged = null;
+  var initialOrder = '';
+
+  list.addEventListener('pointerdown', function(event) {
+    var handle = event.target.closest('[data-reorder-handle]');
+    if (!handle) {
+      return;
+    }
+
+    event.preventDefault();
+    dragged = handle.closest('[data-reorder-item]');
+    initialOrder = order();
+    dragged.classList.add('dragging');
+    handle.setPointerCapture(event.pointerId);
+  });
+
+  list.addEventListener('pointermove', function(event) {
+    if (!dragged) {
+      return;
+    }
+
+    var target = document.elementFromPoint(event.clientX, event.clientY);
+    var item = target && target.closest('[data-reorder-item]');
+    if (!item || item === dragged || item.parentNode !== list) {
+      return;
+    }
+
+    var box = item.getBoundingClientRect();
+    if (event.clientY < box.top + box.height / 2) {
+      list.insertBefore(dragged, item);
+    } else {
+      list.insertBefore(dragged, item.nextSibling);
+    }
+  });
+
+  list.addEventListener('pointerup', drop);
+  list.addEventListener('pointercancel', drop);
+
+  function drop() {
+    if (!dragged) {
+      return;
+    }
+
+    dragged.classList.remove('dragging');
+    dragged = null;
+
+    // The hidden inputs moved together with their items, so submitting the form sends the new order.
+    if (order() !== initialOrder) {
+      form.submit();
+    }
+  }
+
+  function order() {
+    var inputs = list.querySelectorAll('input[name=category_ids]');
+    return Array.prototype.map.call(inputs, function(input) {
+      return input.value;
+    }).join(',');
+  }
+})();
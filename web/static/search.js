@@ -0,0 +1,49 @@
+// Suggests products matching the name typed into the plan form.
+// Results come from `/search-products`, whose rendered suggestions are swapped in, so the markup stays in the templates.
+(function() {
+  var input = document.querySelector('#name');
+  var container = document.querySelector('[data-search-results]');
+  if (!input || !container || !window.fetch || !window.DOMParser) {
+    return;
+  }
+
+  var timeout = null;
+  var latest = 0;
+
+  function search() {
+    var query = input.value;
+    var current = ++latest;
+
+    if (query.trim() === '') {
+      container.innerHTML = '';
+      container.hidden = true;
+      return;
+    }
+
+    fetch('/search-products?q=' + encodeURIComponent(query), { credentials: 'same-origin' })
+      .then(function(response) {
+        if (!response.ok) {
+          throw new Error(response.statusText);
+        }
+
+        return response.text();
+      })
+      .then(function(html) {
+        // Answers can overtake each other, only the one for the latest input is shown.
+        if (current !== latest) {
+          return;
+        }
+
+        var page = new DOMParser().parseFromString(html, 'text/html');
+        var results = page.querySelector('[data-search-results]');
+        container.innerHTML = results ? results.innerHTML : '';
+        container.hidden = !results;
+      })
+      .catch(function() {});
+  }
+
+  input.addEventListener('input', function() {
+    clearTimeout(timeout);
+    timeout = setTimeout(search, 150);
+  });
+})();
@@ -0,0 +1,235 @@
+// Keeps /shop and /plan usable without reception.
+// Pages are loaded from the network first and fall back to the last copy we saw.
+// Check, remove and undo posts that cannot reach the server are queued in IndexedDB and replayed once we are online again.
+// Every queued post keeps its `_idempotency_key`, so replaying a post the server already processed does not change anything.
+
+var PAGES_CACHE = 'pages-v1';
+var STATIC_CACHE = 'static-v6';
+var STATIC_FILES = [
+  '/static/besserliste.css?version=10',
+  '/static/live-updates.js?version=2',
+  '/static/offline.js?version=1',
+  '/static/search.js?version=1',
+  '/static/icon-homescreen.png'
+];
+var CACHED_PAGES = ['/shop', '/plan'];
+var QUEUED_ACTIONS = ['/check-item', '/remove-item', '/undo'];
+var SYNC_TAG = 'replay-queue';
+
+self.addEventListener('install', function(event) {
+  event.waitUntil(
+    caches.open(STATIC_CACHE).then(function(cache) {
+      return cache.addAll(STATIC_FILES);
+    }).then(function() {
+      return self.skipWaiting();
+    })
+  );
+});
+
+self.addEventListener('activate', function(event) {
+  event.waitUntil(
+    caches.keys().then(function(names) {
+      return Promise.all(names.filter(function(name) {
+        return name !== PAGES_CACHE && name !== STATIC_CACHE;
+      }).map(function(name) {
+        return caches.delete(name);
+      }));
+    }).then(function() {
+      return self.clients.claim();
+    })
+  );
+});
+
+self.addEventListener('fetch', function(event) {
+  var url = new URL(event.request.url);
+  if (url.origin !== self.location.origin) {
+    return;
+  }
+
+  if (event.request.method === 'GET' && CACHED_PAGES.indexOf(url.pathname) !== -1) {
+    event.respondWith(networkFirst(event.request));
+  } else if (event.request.method === 'GET' && url.pathname.indexOf('/static/') === 0) {
+    event.respondWith(cacheFirst(event.request));
+  } else if (event.request.method === 'POST' && QUEUED_ACTIONS.indexOf(url.pathname) !== -1) {
+    event.respondWith(postOrQueue(event.request));
+  } else if (event.request.method === 'POST' && url.pathname === '/logout') {
+    event.respondWith(forget().then(function() {
+      return fetch(event.request);
+    }));
+  }
+});
+
+self.addEventListener('sync', function(event) {
+  if (event.tag === SYNC_TAG) {
+    event.waitUntil(replay());
+  }
+});
+
+self.addEventListener('message', function(event) {
+  if (event.data === 'replay') {
+    event.waitUntil(replay());
+  }
+});
+
+function networkFirst(request) {
+  return fetch(request).then(function(response) {
+    // Redirects mean the session expired, we do not want to serve the login form as shopping list.
+    if (response.ok && !response.redirected) {
+      var copy = response.clone();
+      caches.open(PAGES_CACHE).then(function(cache) {
+        cache.put(request, copy);
+      });
+    }
+
+    return response;
+  }).catch(function() {
+    return caches.open(PAGES_CACHE).then(function(cache) {
+      return cache.match(request).then(function(response) {
+        return response || cache.match(request, { ignoreSearch: true });
+      });
+    }).then(function(response) {
+      return response || offlineResponse();
+    });
+  });
+}
+
+function cacheFirst(request) {
+  return caches.match(request).then(function(response) {
+    return response || fetch(request);
+  });
+}
+
+function postOrQueue(request) {
+  var copy = request.clone();
+
+  return replay().then(function(remaining) {
+    if (remaining > 0) {
+      // Keep the order of actions, otherwise an undo could overtake the check it reverts.
+      return queue(copy);
+    }
+
+    return fetch(request).catch(function() {
+      return queue(copy);
+    });
+  });
+}
+
+function queue(request) {
+  return request.text().then(function(body) {
+    return withStore('readwrite', function(store) {
+      store.add({ url: request.url, body: body, queuedAt: Date.now() });
+    });
+  }).then(function() {
+    if (self.registration.sync) {
+      return self.registration.sync.register(SYNC_TAG).catch(function() {});
+    }
+  }).then(function() {
+    notifyClients();
+    return Response.redirect(request.referrer || '/shop', 303);
+  });
+}
+
+var replaying = null;
+
+// replay sends the queued posts in order and resolves with the number of posts still waiting.
+function replay() {
+  if (!replaying) {
+    replaying = entries().then(function(entries) {
+      return replayEntries(entries, 0);
+    }).then(function(remaining) {
+      replaying = null;
+      if (remaining === 0) {
+        notifyClients();
+      }
+      return remaining;
+    }, function(error) {
+      replaying = null;
+      throw error;
+    });
+  }
+
+  return replaying;
+}
+
+function replayEntries(entries, index) {
+  if (index >= entries.length) {
+    return Promise.resolve(0);
+  }
+
+  var entry = entries[index];
+  return fetch(entry.url, {
+    method: 'POST',
+    body: entry.body,
+    credentials: 'same-origin',
+    redirect: 'manual',
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  }).then(function(response) {
+    if (response.status >= 500) {
+      return entries.length - index;
+    }
+
+    // Everything else is final: success, a replay the server already knew or an item someone else changed meanwhile.
+    return withStore('readwrite', function(store) {
+      store.delete(entry.id);
+    }).then(function() {
+      return replayEntries(entries, index + 1);
+    });
+  }, function() {
+    return entries.length - index;
+  });
+}
+
+function entries() {
+  return withStore('readonly', function(store) {
+    return store.getAll();
+  });
+}
+
+function forget() {
+  return Promise.all([
+    caches.delete(PAGES_CACHE),
+    withStore('readwrite', function(store) {
+      store.clear();
+    })
+  ]);
+}
+
+function notifyClients() {
+  return self.clients.matchAll().then(function(clients) {
+    clients.forEach(function(client) {
+      client.postMessage('queue-changed');
+    });
+  });
+}
+
+function offlineResponse() {
+  return new Response('<!doctype html><meta charset="utf-8"><title>Offline - Besserliste</title><p>Keine Verbindung und diese Seite wurde noch nicht gespeichert.</p>', {
+    status: 503,
+    headers: { 'Content-Type': 'text/html; charset=utf-8' }
+  });
+}
+
+function withStore(mode, callback) {
+  return new Promise(function(resolve, reject) {
+    var open = indexedDB.open('besserliste', 1);
+    open.onupgradeneeded = function() {
+      open.result.createObjectStore('queue', { keyPath: 'id', autoIncrement: true });
+    };
+    open.onerror = function() {
+      reject(open.error);
+    };
+    open.onsuccess = function() {
+      var db = open.result;
+      var transaction = db.transaction('queue', mode);
+      var request = callback(transaction.objectStore('queue'));
+      transaction.oncomplete = function() {
+        db.close();
+        resolve(request ? request.result : undefined);
+      };
+      transaction.onerror = function() {
+        db.close();
+        reject(transaction.error);
+      };
+    };
+  });
+}